@@ -0,0 +1,98 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// BatchRefreshFunc returns many keyed values, each with its own expiry, which are
+// acquired together, e.g. the credentials of several roles returned by a single call.
+type BatchRefreshFunc[K comparable, V any] func(context.Context) (map[K]*Refreshable[V], error)
+
+// BatchRefresher represents an entity in charge of maintaining many expiring values, keyed by K,
+// which are refreshed together. It is a Refresher of the whole batch, which is refreshed as per the
+// earliest issuance and expiry within the batch, such that no value in it is refreshed late.
+type BatchRefresher[K comparable, V any] interface {
+	Refresher[map[K]*Refreshable[V]]
+
+	// GetKey returns the current value for the given key, enforcing its own expiry. If the
+	// batch has no value for the key, the returned error is a *KeyError wrapping ErrNoValue.
+	// If the value for the key is expired, it is returned alongside a *KeyError wrapping ErrStale.
+	GetKey(key K) (*Refreshable[V], error)
+
+	// GetKeyFresh returns the current value for the given key if the batch is not expired.
+	// Otherwise it triggers an immediate refresh of the batch (or joins one already in progress)
+	// and blocks until it completes or the given context is done, whichever happens first. Keys
+	// missing from an unexpired batch do not trigger refreshes.
+	GetKeyFresh(ctx context.Context, key K) (*Refreshable[V], error)
+}
+
+// batchRefresher is the private, default implementation of the BatchRefresher interface.
+type batchRefresher[K comparable, V any] struct {
+	*refresher[map[K]*Refreshable[V]]
+}
+
+// NewBatchRefresher returns a BatchRefresher initialized with the given BatchRefreshFunc and
+// Option(s). The recommended usage is to call WaitForInitialValue(<timeout>) immediately afterwards.
+func NewBatchRefresher[K comparable, V any](
+	refreshFunc BatchRefreshFunc[K, V],
+	opts ...Option[map[K]*Refreshable[V]],
+) BatchRefresher[K, V] {
+	return &batchRefresher[K, V]{
+		refresher: NewRefresher(func(ctx context.Context) (*Refreshable[map[K]*Refreshable[V]], error) {
+			values, err := refreshFunc(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return batchOf(values)
+		}, opts...).(*refresher[map[K]*Refreshable[V]]),
+	}
+}
+
+// batchOf wraps keyed values in a single Refreshable, issued when the earliest
+// of them was issued and expiring when the earliest of them expires.
+func batchOf[K comparable, V any](values map[K]*Refreshable[V]) (*Refreshable[map[K]*Refreshable[V]], error) {
+	var issuedAt, expiresAt time.Time
+	for _, value := range values {
+		if value == nil {
+			return nil, errors.New("batch refresh returned a nil value")
+		}
+		if issuedAt.IsZero() || value.IssuedAt.Before(issuedAt) {
+			issuedAt = value.IssuedAt
+		}
+		if expiresAt.IsZero() || value.ExpiresAt.Before(expiresAt) {
+			expiresAt = value.ExpiresAt
+		}
+	}
+	if len(values) == 0 {
+		return nil, errors.New("batch refresh returned no values")
+	}
+	return &Refreshable[map[K]*Refreshable[V]]{Value: values, IssuedAt: issuedAt, ExpiresAt: expiresAt}, nil
+}
+
+// GetKey returns the current value for the given key, enforcing its own expiry.
+func (b *batchRefresher[K, V]) GetKey(key K) (*Refreshable[V], error) {
+	b.ensureStarted()
+	batch := b.getCurrent()
+	if batch == nil {
+		return nil, &KeyError[K]{Key: key, Err: ErrNoValue}
+	}
+	value, ok := batch.Value[key]
+	if !ok {
+		return nil, &KeyError[K]{Key: key, Err: ErrNoValue}
+	}
+	if isExpired(value, time.Now()) {
+		return value, &KeyError[K]{Key: key, Err: ErrStale}
+	}
+	return value, nil
+}
+
+// GetKeyFresh returns the current value for the given key if the batch is not
+// expired, otherwise it waits for an immediate (possibly shared) refresh of the batch.
+func (b *batchRefresher[K, V]) GetKeyFresh(ctx context.Context, key K) (*Refreshable[V], error) {
+	if _, err := b.GetFresh(ctx); err != nil {
+		return nil, &KeyError[K]{Key: key, Err: err}
+	}
+	return b.GetKey(key)
+}
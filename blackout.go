@@ -0,0 +1,69 @@
+package refresh
+
+import (
+	"log/slog"
+	"time"
+)
+
+// maxBlackoutDeferrals bounds the number of times a refresh can be deferred
+// past consecutive (or overlapping) blackout windows.
+const maxBlackoutDeferrals = 16
+
+// WithBlackoutWindows is the refresher Option to set TimeWindow(s) during which scheduled
+// refreshes are not carried out (e.g. an issuer's nightly maintenance or change-freeze
+// periods). A refresh scheduled within a blackout window is deferred until the window
+// ends, unless the value would expire within the window, in which case it is not deferred.
+// The same applies to retries of failed refreshes. Deferrals are logged, reported to the
+// WithOnRefreshDeferred callback, and published as EventRefreshDeferred events.
+func WithBlackoutWindows[T any](windows ...TimeWindow) Option[T] {
+	return func(r *refresher[T]) { r.blackoutWindows = append(r.blackoutWindows, windows...) }
+}
+
+// WithOnRefreshDeferred is the refresher Option to set a callback function to be fired
+// after a scheduled refresh is deferred due to a blackout window, with the originally
+// scheduled time and the time to which the refresh was deferred.
+func WithOnRefreshDeferred[T any](onRefreshDeferred func(scheduledAt time.Time, deferredTo time.Time)) Option[T] {
+	return func(r *refresher[T]) { r.onRefreshDeferred = onRefreshDeferred }
+}
+
+// deferForBlackouts returns the given refresh time, deferred past any blackout windows,
+// reporting the deferral (if any). It must only be called from the refresher's own
+// (tracked) go-routines, as it publishes events.
+func (r *refresher[T]) deferForBlackouts(refreshable *Refreshable[T], refreshAt time.Time) time.Time {
+	deferredTo := r.deferredPastBlackouts(refreshable, refreshAt)
+	if !deferredTo.Equal(refreshAt) {
+		r.log(slog.LevelInfo, "refresh deferred due to blackout window", "scheduled_at", refreshAt, "deferred_to", deferredTo)
+		r.emit(func() { r.onRefreshDeferred(refreshAt, deferredTo) })
+		r.publish(Event[T]{Kind: EventRefreshDeferred, Refreshable: refreshable, RefreshAt: deferredTo, ScheduledAt: refreshAt})
+	}
+	return deferredTo
+}
+
+// deferredPastBlackouts returns the given refresh time, deferred past any blackout windows
+// ending before the given value expires. Refreshes of no value (nil) are never deferred.
+func (r *refresher[T]) deferredPastBlackouts(refreshable *Refreshable[T], refreshAt time.Time) time.Time {
+	if len(r.blackoutWindows) == 0 || refreshable == nil {
+		return refreshAt
+	}
+
+	deferredTo := refreshAt
+	for i := 0; i < maxBlackoutDeferrals; i++ {
+		end, ok := r.blackoutEnd(deferredTo)
+		if !ok || end.After(refreshable.ExpiresAt) {
+			break
+		}
+		deferredTo = end
+	}
+	return deferredTo
+}
+
+// blackoutEnd returns the latest end of the blackout windows containing the given time, if any.
+func (r *refresher[T]) blackoutEnd(t time.Time) (time.Time, bool) {
+	var latest time.Time
+	for _, window := range r.blackoutWindows {
+		if end, ok := windowContains(window, t); ok && end.After(latest) {
+			latest = end
+		}
+	}
+	return latest, !latest.IsZero()
+}
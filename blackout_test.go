@@ -0,0 +1,71 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlackoutWindowsDeferRetries(t *testing.T) {
+	began := time.Now()
+	windowStart, windowEnd := began.Add(150*time.Millisecond), began.Add(450*time.Millisecond)
+
+	var (
+		mu    sync.Mutex
+		calls []time.Time
+	)
+	refreshFunc := func(context.Context) (*Refreshable[int], error) {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		calls = append(calls, now)
+		if len(calls) == 2 {
+			return nil, errors.New("issuer unavailable")
+		}
+		refreshable := &Refreshable[int]{Value: len(calls), IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+		if len(calls) == 1 {
+			refreshable.RefreshAt = began.Add(50 * time.Millisecond)
+		}
+		return refreshable, nil
+	}
+
+	r := NewRefresher(refreshFunc,
+		WithRetryDelay[int](100*time.Millisecond),
+		WithBlackoutWindows[int](NewAbsoluteTimeWindow(windowStart, windowEnd)),
+	)
+	defer r.Stop()
+	events := r.Events()
+
+	// the scheduled refresh (at 50ms) fails, and its retry (at ~150ms) is deferred past the window
+	timeout := time.After(2 * time.Second)
+	for deferred := false; !deferred; {
+		select {
+		case event := <-events:
+			if event.Kind != EventRefreshDeferred {
+				continue
+			}
+			deferred = true
+			if !event.RefreshAt.Equal(windowEnd) || event.ScheduledAt.Before(windowStart) || event.Refreshable == nil {
+				t.Errorf("refresh deferred from %s to %s, expected a retry deferred to the end of the window (%s)",
+					event.ScheduledAt.Sub(began), event.RefreshAt.Sub(began), windowEnd.Sub(began))
+			}
+		case <-timeout:
+			t.Fatal("retry was not deferred")
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for r.GetCurrent().Value != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("refresh was not retried after the window")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if calls[2].Before(windowEnd) {
+		t.Errorf("refresh retried at %s, within the blackout window", calls[2].Sub(began))
+	}
+}
@@ -0,0 +1,16 @@
+package refresh
+
+// WithStoreBeforeSwap is the refresher Option to only make a refreshed value current once it
+// was written to Storage, such that the previous value remains current (and every value ever
+// handed out is also in Storage) until the new one is both validated and stored. A failed
+// storage write then fails the refresh, which is retried as any other failed refresh.
+//
+// Without this option, new values become current right away and are stored in the background.
+func WithStoreBeforeSwap[T any]() Option[T] {
+	return func(r *refresher[T]) { r.storeBeforeSwap = true }
+}
+
+// GetPrevious returns the value which was current before the current one.
+func (r *refresher[T]) GetPrevious() *Refreshable[T] {
+	return r.previous.Load()
+}
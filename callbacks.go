@@ -0,0 +1,61 @@
+package refresh
+
+// WithSynchronousCallbacks is the refresher Option to run event handlers (the On* options and
+// Observers) in the go-routine carrying out the refresh (or initialization), rather than in a
+// background go-routine. A refresh then does not complete before its handlers have returned,
+// which applies backpressure on slow handlers. Storage writes are also carried out synchronously,
+// such that their events are ordered with respect to those of the refresh which produced the
+// value being stored.
+//
+// Handlers must not block indefinitely nor call methods of the refresher which wait for a
+// refresh (e.g. GetFresh or ForceRefresh), as that refresh is the one running the handler.
+func WithSynchronousCallbacks[T any]() Option[T] {
+	return func(r *refresher[T]) { r.synchronousCallbacks = true }
+}
+
+// emit runs an event handler, either in the background or synchronously, as per the
+// refresher's configuration. Panics in the handler are recovered and reported. It must
+// only be called from the refresher's own (tracked) go-routines.
+//
+// Background handlers are queued and run in order by a single tracked go-routine, which
+// is only running while there are queued handlers, rather than each in a new go-routine.
+// A slow handler thus delays subsequent ones, but never the refresher itself.
+func (r *refresher[T]) emit(handler func()) {
+	if r.synchronousCallbacks {
+		_ = r.guard("event handler", handler)
+		return
+	}
+
+	r.dispatchMu.Lock()
+	r.dispatchQueue = append(r.dispatchQueue, handler)
+	if r.dispatching {
+		r.dispatchMu.Unlock()
+		return
+	}
+	r.dispatching = true
+	r.dispatchMu.Unlock()
+
+	r.spawn(r.dispatch)
+}
+
+// dispatch runs queued event handlers until there are none left. The queue's backing
+// arrays are swapped back and forth (rather than reallocated) between batches.
+func (r *refresher[T]) dispatch() {
+	var batch []func()
+	for {
+		r.dispatchMu.Lock()
+		if len(r.dispatchQueue) == 0 {
+			r.dispatchQueue = batch[:0]
+			r.dispatching = false
+			r.dispatchMu.Unlock()
+			return
+		}
+		batch, r.dispatchQueue = r.dispatchQueue, batch[:0]
+		r.dispatchMu.Unlock()
+
+		for i, handler := range batch {
+			_ = r.guard("event handler", handler)
+			batch[i] = nil // release the handler's closure
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package refresh
+
+import "reflect"
+
+// WithOnChange is the refresher Option to set a function which is called whenever a new value
+// becomes current which is different from the previous one (including the initial value), as per
+// the given equality function. A nil equality function compares values with reflect.DeepEqual.
+//
+// This is useful when upstreams hand out the same value repeatedly (e.g. the same token until it
+// is close to expiring) and consumers do expensive work on rotation, such as rebuilding connection
+// pools. Unlike WithOnRefreshSuccess, values with only new timestamps do not trigger the function.
+func WithOnChange[T any](equal func(old, new T) bool, onChange func(*Refreshable[T])) Option[T] {
+	return func(r *refresher[T]) {
+		if equal == nil {
+			equal = func(old, new T) bool { return reflect.DeepEqual(old, new) }
+		}
+		r.onChangeEqual = equal
+		r.onChange = onChange
+	}
+}
+
+// notifyChange calls the refresher's onChange function if the value changed.
+// It must only be called from the refresher's own (tracked) go-routines.
+func (r *refresher[T]) notifyChange(oldValue, newValue *Refreshable[T]) {
+	if r.onChange == nil || newValue == nil {
+		return
+	}
+	if oldValue != nil {
+		equal := false
+		_ = r.guard("change equality function", func() { equal = r.onChangeEqual(oldValue.Value, newValue.Value) })
+		if equal {
+			return
+		}
+	}
+	r.emit(func() { r.onChange(newValue) })
+}
@@ -0,0 +1,39 @@
+package refresh
+
+// WithMaxConsecutiveFailures is the refresher Option to give up on background refreshes after
+// the given number of consecutive failed refresh attempts, rather than retrying them forever.
+// When the limit is reached, the given function (if not nil) is called with the last error, and
+// the refresher reports itself as exhausted in its Stats (and thus as unhealthy in a Manager).
+//
+// Explicit refreshes (e.g. via GetFresh or ForceRefresh) are still carried out while exhausted,
+// and a successful one resets the count of consecutive failures and resumes background refreshes.
+func WithMaxConsecutiveFailures[T any](maxConsecutiveFailures int, onExhausted func(error)) Option[T] {
+	return func(r *refresher[T]) {
+		if onExhausted == nil {
+			onExhausted = func(err error) { /* NOOP */ }
+		}
+		r.maxConsecutiveFailures = maxConsecutiveFailures
+		r.onExhausted = onExhausted
+	}
+}
+
+// exhaustLocked marks the refresher as exhausted once the number of consecutive failures reaches
+// the limit, returning true if it just became exhausted. r.statsMu must be held.
+func (r *refresher[T]) exhaustLocked() bool {
+	if r.maxConsecutiveFailures <= 0 || r.stats.Exhausted {
+		return false
+	}
+	if r.stats.ConsecutiveFailures < int64(r.maxConsecutiveFailures) {
+		return false
+	}
+	r.stats.Exhausted = true
+	return true
+}
+
+// isExhausted returns true if the refresher gave up on background refreshes.
+func (r *refresher[T]) isExhausted() bool {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	return r.stats.Exhausted
+}
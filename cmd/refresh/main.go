@@ -0,0 +1,38 @@
+// Command refresh provides tooling for users of the refresh library.
+//
+// Usage:
+//
+//	refresh simulate [flags]
+//
+// Run "refresh simulate -h" for details on the available flags.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: refresh <command> [flags]
+
+commands:
+  simulate    simulate a fleet's refresh cadence for a given strategy and issuer lifetime distribution
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "simulate":
+		if err := simulate(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "simulate: %v\n", err)
+			os.Exit(1)
+		}
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/strategies"
+)
+
+// simulation is the configuration of a simulation.
+type simulation struct {
+	strategy       string
+	windowMin      float64
+	windowMax      float64
+	staticDuration time.Duration
+
+	lifetime       time.Duration
+	lifetimeJitter time.Duration
+
+	instances   int
+	startSpread time.Duration
+	horizon     time.Duration
+	bucket      time.Duration
+	seed        int64
+}
+
+// simulate runs the simulate command.
+func simulate(args []string, out io.Writer) error {
+	var sim simulation
+
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	fs.StringVar(&sim.strategy, "strategy", "default", "refresh strategy: default, random-window, lifetime-left, lifetime-spent")
+	fs.Float64Var(&sim.windowMin, "window-min", 0.5, "minimum fraction of lifetime elapsed (random-window strategy)")
+	fs.Float64Var(&sim.windowMax, "window-max", 0.75, "maximum fraction of lifetime elapsed (random-window strategy)")
+	fs.DurationVar(&sim.staticDuration, "static", 10*time.Minute, "lifetime left or spent (lifetime-left and lifetime-spent strategies)")
+	fs.DurationVar(&sim.lifetime, "lifetime", time.Hour, "mean lifetime of values returned by the issuer")
+	fs.DurationVar(&sim.lifetimeJitter, "lifetime-jitter", 0, "maximum deviation (uniformly distributed) from the mean lifetime")
+	fs.IntVar(&sim.instances, "instances", 100, "number of instances (refreshers) in the fleet")
+	fs.DurationVar(&sim.startSpread, "start-spread", 0, "period over which instances start (0 simulates a simultaneous restart)")
+	fs.DurationVar(&sim.horizon, "horizon", 24*time.Hour, "simulated period of time")
+	fs.DurationVar(&sim.bucket, "bucket", 5*time.Minute, "granularity of the issuer request rate plot")
+	fs.Int64Var(&sim.seed, "seed", 1, "seed for the simulation's randomness")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	strategy, err := sim.refreshStrategy()
+	if err != nil {
+		return err
+	}
+	if sim.instances < 1 || sim.lifetime <= 0 || sim.horizon <= 0 || sim.bucket <= 0 {
+		return fmt.Errorf("instances, lifetime, horizon, and bucket must be positive")
+	}
+
+	sim.run(strategy).print(out, sim)
+	return nil
+}
+
+// refreshStrategy returns the configured refresh strategy.
+func (sim *simulation) refreshStrategy() (refresh.RefreshStrategy[struct{}], error) {
+	switch sim.strategy {
+	case "default":
+		return refresh.DefaultRefreshStrategy[struct{}](), nil
+	case "random-window":
+		return strategies.NewRandomWithinLifetimeWindow[struct{}](sim.windowMin, sim.windowMax), nil
+	case "lifetime-left":
+		return strategies.NewStaticLifetimeLeft[struct{}](sim.staticDuration), nil
+	case "lifetime-spent":
+		return strategies.NewStaticLifetimeSpent[struct{}](sim.staticDuration), nil
+	default:
+		return nil, fmt.Errorf("unsupported strategy %q", sim.strategy)
+	}
+}
+
+// results are the results of a simulation.
+type results struct {
+	intervals     []time.Duration
+	firstRefresh  []time.Duration
+	requestCounts []int
+}
+
+// run runs the simulation with the given strategy.
+//
+// Strategies compute refresh times relative to the current (wall clock) time, so every
+// simulated refresh is issued "now" and the resulting interval is applied to the instance's
+// simulated clock.
+func (sim *simulation) run(strategy refresh.RefreshStrategy[struct{}]) *results {
+	rng := rand.New(rand.NewSource(sim.seed))
+	res := &results{requestCounts: make([]int, int(math.Ceil(float64(sim.horizon)/float64(sim.bucket))))}
+
+	for i := 0; i < sim.instances; i++ {
+		var clock time.Duration
+		if sim.startSpread > 0 {
+			clock = time.Duration(rng.Int63n(int64(sim.startSpread)))
+		}
+		for refreshes := 0; clock < sim.horizon; refreshes++ {
+			res.requestCounts[int(clock/sim.bucket)]++
+
+			lifetime := sim.lifetime
+			if sim.lifetimeJitter > 0 {
+				lifetime += time.Duration(rng.Int63n(2*int64(sim.lifetimeJitter))) - sim.lifetimeJitter
+			}
+			if lifetime <= 0 {
+				lifetime = time.Second
+			}
+
+			now := time.Now()
+			refreshAt := strategy.GetRefreshAt(&refresh.Refreshable[struct{}]{IssuedAt: now, ExpiresAt: now.Add(lifetime)})
+			interval := refreshAt.Sub(now)
+			if interval < time.Second {
+				interval = time.Second // an immediate refresh still takes time
+			}
+
+			res.intervals = append(res.intervals, interval)
+			if refreshes == 0 {
+				res.firstRefresh = append(res.firstRefresh, clock+interval)
+			}
+			clock += interval
+		}
+	}
+	return res
+}
+
+// print writes a human readable report of the results.
+func (res *results) print(out io.Writer, sim simulation) {
+	sort.Slice(res.intervals, func(i, j int) bool { return res.intervals[i] < res.intervals[j] })
+	sort.Slice(res.firstRefresh, func(i, j int) bool { return res.firstRefresh[i] < res.firstRefresh[j] })
+
+	total, peak := 0, 0
+	for _, count := range res.requestCounts {
+		total += count
+		if count > peak {
+			peak = count
+		}
+	}
+	mean := float64(total) / float64(len(res.requestCounts))
+	perMinute := float64(time.Minute) / float64(sim.bucket)
+
+	fmt.Fprintf(out, "simulated %d instance(s) over %s with strategy %q\n\n", sim.instances, sim.horizon, sim.strategy)
+	fmt.Fprintf(out, "refresh interval:     min %s  p50 %s  p95 %s  max %s\n",
+		percentile(res.intervals, 0), percentile(res.intervals, 0.5), percentile(res.intervals, 0.95), percentile(res.intervals, 1))
+	fmt.Fprintf(out, "first refresh spread: %s (p5 %s, p95 %s after start)\n",
+		percentile(res.firstRefresh, 0.95)-percentile(res.firstRefresh, 0.05), percentile(res.firstRefresh, 0.05), percentile(res.firstRefresh, 0.95))
+	fmt.Fprintf(out, "issuer requests:      %d total, mean %.2f/min, peak %.2f/min (peak/mean %.1fx)\n\n",
+		total, mean*perMinute, float64(peak)*perMinute, float64(peak)/math.Max(mean, 1e-9))
+
+	const width = 50
+	fmt.Fprintf(out, "issuer requests per %s:\n", sim.bucket)
+	for i, count := range res.requestCounts {
+		bar := 0
+		if peak > 0 {
+			bar = int(math.Round(float64(count) / float64(peak) * width))
+		}
+		fmt.Fprintf(out, "%10s |%-*s %d\n", time.Duration(i)*sim.bucket, width, strings.Repeat("#", bar), count)
+	}
+}
+
+// percentile returns the p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(math.Round(p*float64(len(sorted)-1)))].Round(time.Second)
+}
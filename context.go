@@ -0,0 +1,23 @@
+package refresh
+
+import "context"
+
+// WithRefreshContext is the refresher Option to add a function which decorates the context of
+// every refresh (and renewal) attempt before it is passed to the RefreshFunc (or RenewFunc), e.g.
+// with a deadline, trace baggage, authentication metadata, or logger values. Decorators are
+// applied in the order in which they were added.
+//
+// The context passed to decorators is canceled when the refresher is stopped, and decorators
+// must derive the context they return from it.
+func WithRefreshContext[T any](decorate func(context.Context) context.Context) Option[T] {
+	return func(r *refresher[T]) { r.refreshContexts = append(r.refreshContexts, decorate) }
+}
+
+// refreshContext returns the context for a refresh attempt, decorated
+// as per the refresher's configuration.
+func (r *refresher[T]) refreshContext(ctx context.Context) context.Context {
+	for _, decorate := range r.refreshContexts {
+		ctx = decorate(ctx)
+	}
+	return ctx
+}
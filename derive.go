@@ -0,0 +1,62 @@
+package refresh
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Map returns a Refresher of values derived from those of the given source Refresher with the
+// given function, e.g. a parsed public key from a refreshed key set document, or a configured
+// client from a refreshed token. Derived values inherit the IssuedAt, ExpiresAt, RefreshAt, and
+// NotBefore of the source values they are derived from, and are re-derived whenever the source
+// has a new value. Failures to derive a value are treated as refresh failures of the derived
+// Refresher, and are retried as per its Option(s).
+//
+// Stopping the derived Refresher does not stop the source, but stopping the source stops the
+// derived Refresher.
+func Map[T, U any](source Refresher[T], fn func(T) (U, error), opts ...Option[U]) Refresher[U] {
+	// derived values are refreshed whenever the source has a new value, and otherwise
+	// only when they expire (at which point the source is expected to have refreshed)
+	defaults := []Option[U]{
+		WithRefreshStrategy(RefreshStrategyFromFunction(func(v *Refreshable[U]) time.Time { return v.ExpiresAt })),
+	}
+	r := NewRefresher(func(ctx context.Context) (*Refreshable[U], error) {
+		src, err := source.GetFresh(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source value: %v", err)
+		}
+		value, err := fn(src.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive value: %v", err)
+		}
+		return &Refreshable[U]{
+			Value:     value,
+			IssuedAt:  src.IssuedAt,
+			ExpiresAt: src.ExpiresAt,
+			RefreshAt: src.RefreshAt,
+			NotBefore: src.NotBefore,
+		}, nil
+	}, append(defaults, opts...)...).(*refresher[U])
+
+	// the derived refresh is not awaited, as that would stall the source's event handlers
+	unsubscribe := source.Subscribe(func(*Refreshable[T]) { r.trigger() })
+	go func() {
+		defer unsubscribe()
+		select {
+		case <-source.Done():
+			r.Stop()
+		case <-r.Done():
+		}
+	}()
+	return r
+}
+
+// trigger makes the refresher refresh as soon as possible, as if its refresh were due, without
+// waiting for it. Triggers received while a triggered refresh is pending are coalesced into it.
+func (r *refresher[T]) trigger() {
+	select {
+	case r.triggered <- struct{}{}:
+	default:
+	}
+}
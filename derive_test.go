@@ -0,0 +1,45 @@
+package refresh
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMapDoesNotStallSource(t *testing.T) {
+	refreshFunc, _ := counter(time.Hour)
+	source := NewRefresher(refreshFunc, WithSynchronousCallbacks[int]())
+	defer source.Stop()
+
+	release := make(chan struct{})
+	derived := Map(source, func(v int) (int, error) {
+		if v > 1 {
+			<-release // derivations of new source values block until released
+		}
+		return v * 10, nil
+	})
+	defer derived.Stop()
+	if err := derived.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		_, _ = source.ForceRefresh(context.Background())
+	}()
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("source refresh waited for the derived refresh")
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for derived.GetCurrent().Value != 20 {
+		if time.Now().After(deadline) {
+			t.Fatalf("derived value is %d, expected it to follow the source", derived.GetCurrent().Value)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
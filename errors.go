@@ -0,0 +1,81 @@
+package refresh
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoValue is returned when a Refresher does not (yet) hold a value.
+	ErrNoValue = errors.New("no value available")
+
+	// ErrStopped is the cause reported by a Refresher which was stopped with Stop.
+	ErrStopped = errors.New("refresher stopped")
+
+	// ErrStale is returned when a Refresher's current value is expired.
+	ErrStale = errors.New("current value is expired")
+
+	// ErrInitTimeout is returned when WaitForInitialValue times out.
+	ErrInitTimeout = errors.New("timed out waiting for initial value")
+
+	// ErrRefreshFailed is returned when a refresh (including the initial one) fails.
+	ErrRefreshFailed = errors.New("refresh failed")
+)
+
+// Error is the error returned by refreshers which have a name (see WithName), or whose errors
+// have an underlying cause. It matches (with errors.Is and errors.As) both its sentinel error
+// (e.g. ErrRefreshFailed) and its cause (e.g. the error returned by the RefreshFunc).
+type Error struct {
+	// Name is the name of the refresher, if any.
+	Name string
+
+	// Sentinel is one of ErrNoValue, ErrStopped, ErrStale, ErrInitTimeout, or ErrRefreshFailed.
+	Sentinel error
+
+	// Cause is the underlying cause, if any.
+	Cause error
+}
+
+// Error returns the error's message.
+func (e *Error) Error() string {
+	msg := e.Sentinel.Error()
+	if cause, ok := e.Cause.(*Error); ok && cause.Name == e.Name {
+		msg = fmt.Sprintf("%s: %v", msg, &Error{Sentinel: cause.Sentinel, Cause: cause.Cause}) // named once
+	} else if e.Cause != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Cause)
+	}
+	if e.Name != "" {
+		msg = fmt.Sprintf("refresher %q: %s", e.Name, msg)
+	}
+	return msg
+}
+
+// Unwrap returns the error's sentinel error and cause.
+func (e *Error) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Sentinel}
+	}
+	return []error{e.Sentinel, e.Cause}
+}
+
+// WithName is the refresher Option to name the refresher, such that its errors and
+// logs are distinguishable from those of other refreshers in the same process.
+func WithName[T any](name string) Option[T] {
+	return func(r *refresher[T]) { r.name = name }
+}
+
+// wrapErr returns an error matching the given sentinel error and cause, which is
+// the sentinel error itself if the refresher has no name and there is no cause.
+func (r *refresher[T]) wrapErr(sentinel error, cause error) error {
+	var e *Error
+	if errors.As(cause, &e) && errors.Is(cause, sentinel) {
+		return cause // already wrapped
+	}
+	if cause == sentinel {
+		cause = nil
+	}
+	if cause == nil && r.name == "" {
+		return sentinel
+	}
+	return &Error{Name: r.name, Sentinel: sentinel, Cause: cause}
+}
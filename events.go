@@ -0,0 +1,173 @@
+package refresh
+
+import (
+	"context"
+	"time"
+)
+
+// EventKind represents the kind of an Event.
+type EventKind int
+
+const (
+	// EventRefreshSucceeded is the kind of events for successful refreshes.
+	EventRefreshSucceeded EventKind = iota + 1
+
+	// EventRefreshFailed is the kind of events for failed refreshes.
+	EventRefreshFailed
+
+	// EventStorageRead is the kind of events for readings from storage, successful or not.
+	EventStorageRead
+
+	// EventStorageWrite is the kind of events for writings to storage, successful or not.
+	EventStorageWrite
+
+	// EventExpired is the kind of events for values expiring without replacement. Expiry is
+	// not tracked by refreshers with an events buffer size of zero and no expiry callback.
+	EventExpired
+
+	// EventStopped is the kind of the last event of a refresher, sent once it has stopped.
+	EventStopped
+
+	// EventSinkWrite is the kind of events for writings to Sinks (see WithSink), successful or not.
+	EventSinkWrite
+
+	// EventRefreshDeferred is the kind of events for scheduled refreshes
+	// (or retries) deferred due to blackout windows (see WithBlackoutWindows).
+	EventRefreshDeferred
+)
+
+// String returns the name of the EventKind.
+func (k EventKind) String() string {
+	switch k {
+	case EventRefreshSucceeded:
+		return "refresh_succeeded"
+	case EventRefreshFailed:
+		return "refresh_failed"
+	case EventStorageRead:
+		return "storage_read"
+	case EventStorageWrite:
+		return "storage_write"
+	case EventExpired:
+		return "expired"
+	case EventStopped:
+		return "stopped"
+	case EventSinkWrite:
+		return "sink_write"
+	case EventRefreshDeferred:
+		return "refresh_deferred"
+	default:
+		return "unknown"
+	}
+}
+
+// Event represents something which happened to a refresher. Which fields are set depends on its Kind.
+type Event[T any] struct {
+	// Kind is the kind of the event.
+	Kind EventKind
+
+	// Time is when the event occurred.
+	Time time.Time
+
+	// Refreshable is the value refreshed, read, written, expired, or whose refresh was
+	// deferred, nil for failures.
+	Refreshable *Refreshable[T]
+
+	// Summary is the summary of the Refreshable, as rendered by the refresher's
+	// Redactor (see WithRedactor). It is empty if there is no Redactor.
+	Summary string
+
+	// RefreshAt is the time of the next scheduled refresh, set for successful
+	// refreshes, and the time to which a refresh was deferred for deferrals.
+	RefreshAt time.Time
+
+	// ScheduledAt is the time for which a deferred refresh was originally scheduled, set for deferrals.
+	ScheduledAt time.Time
+
+	// Source is the source of the new value, set for successful refreshes: 0 for the
+	// refresher's RefreshFunc (or RenewFunc), and i for its i-th fallback RefreshFunc
+	// (see WithFallbackRefreshFunc).
+	Source int
+
+	// Duration is how long the operation took, set for refresh, storage, and sink events.
+	Duration time.Duration
+
+	// Err is the reason why the operation failed, set for failures, or
+	// the reason why the refresher stopped (see Cause) for EventStopped.
+	Err error
+}
+
+// DefaultEventBufferSize is the default capacity of the channel returned by Events.
+const DefaultEventBufferSize = 64
+
+// WithEventBufferSize is the refresher Option to override the default capacity
+// (DefaultEventBufferSize) of the channel returned by Events. With a capacity
+// of zero, events are only delivered while a consumer is waiting for them.
+func WithEventBufferSize[T any](size int) Option[T] {
+	return func(r *refresher[T]) { r.eventBufferSize = size }
+}
+
+// Events returns the channel on which the refresher sends its events, creating it on the first
+// call such that refreshers whose events are never consumed do not buffer (and retain) them.
+func (r *refresher[T]) Events() <-chan Event[T] {
+	r.eventsMu.Lock()
+	defer r.eventsMu.Unlock()
+
+	if events := r.events.Load(); events != nil {
+		return *events
+	}
+	events := make(chan Event[T], r.eventBufferSize)
+	r.events.Store(&events)
+	if r.eventsStopped {
+		r.sendStopped(events)
+	}
+	return events
+}
+
+// publish sends an event on the events channel (if any), without blocking. It must only be
+// called from the refresher's own (tracked) go-routines, or by drain() before closing the channel.
+func (r *refresher[T]) publish(event Event[T]) {
+	events := r.events.Load()
+	if events == nil {
+		return
+	}
+	event.Time = time.Now()
+	event.Summary, _ = r.redact(event.Refreshable)
+	select {
+	case *events <- event:
+	default: // dropped, the buffer is full
+	}
+}
+
+// publishStopped sends the refresher's last event and closes the events channel, or marks
+// the refresher as stopped for a channel created later. It must only be called by drain(),
+// once all tracked go-routines have exited.
+func (r *refresher[T]) publishStopped() {
+	r.eventsMu.Lock()
+	defer r.eventsMu.Unlock()
+
+	r.eventsStopped = true
+	if events := r.events.Load(); events != nil {
+		r.sendStopped(*events)
+	}
+}
+
+// sendStopped sends EventStopped on the given events channel and closes it. If the channel's
+// buffer is full, its oldest event is dropped to make room, such that EventStopped is never
+// dropped (unless the channel is unbuffered and no consumer is waiting, in which case its
+// closing is what signals the stop).
+func (r *refresher[T]) sendStopped(events chan Event[T]) {
+	event := Event[T]{Kind: EventStopped, Time: time.Now(), Err: context.Cause(r.refreshCtx)}
+	select {
+	case events <- event:
+	default:
+		select {
+		case <-events: // no other event is sent concurrently, so this makes room for EventStopped
+		default:
+		}
+		select {
+		case events <- event:
+		default: // unbuffered, and no consumer is waiting
+		}
+	}
+	close(events)
+}
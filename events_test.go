@@ -0,0 +1,87 @@
+package refresh
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventsStoppedDeliveredWhenBufferFull(t *testing.T) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc, WithEventBufferSize[int](2))
+	events := r.Events()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := r.ForceRefresh(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.StopAndWait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var last Event[int]
+	n := 0
+	for event := range events {
+		last = event
+		n++
+	}
+	if n != 2 {
+		t.Errorf("received %d events, expected the buffer's capacity (2)", n)
+	}
+	if last.Kind != EventStopped {
+		t.Errorf("last event is %s, expected %s", last.Kind, EventStopped)
+	}
+}
+
+func TestEventsCreatedOnFirstCall(t *testing.T) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc)
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if r.(*refresher[int]).events.Load() != nil {
+		t.Fatal("events channel created before the first call to Events")
+	}
+
+	events := r.Events()
+	if _, err := r.ForceRefresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Stop()
+
+	var kinds []EventKind
+	for event := range events {
+		kinds = append(kinds, event.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != EventRefreshSucceeded || kinds[1] != EventStopped {
+		t.Errorf("received events %v, expected only those after the first call", kinds)
+	}
+}
+
+func TestEventsAfterStop(t *testing.T) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc, WithEventBufferSize[int](0))
+	if err := r.StopAndWait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case _, ok := <-r.Events():
+		if ok {
+			t.Error("expected no events on an unbuffered channel created after stopping")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("events channel not closed after stopping")
+	}
+
+	r = NewRefresher(refreshFunc)
+	if err := r.StopAndWait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if event := <-r.Events(); event.Kind != EventStopped {
+		t.Errorf("got %s, expected %s", event.Kind, EventStopped)
+	}
+}
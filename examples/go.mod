@@ -0,0 +1,36 @@
+module github.com/adrianosela/refresh/examples
+
+go 1.22
+
+require (
+	github.com/adrianosela/refresh v0.0.0-00010101000000-000000000000
+	github.com/adrianosela/refresh/integrations/oauth2 v0.0.0-00010101000000-000000000000
+	github.com/adrianosela/refresh/metrics/otel v0.0.0-00010101000000-000000000000
+	github.com/adrianosela/refresh/metrics/prometheus v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.22.0
+	golang.org/x/oauth2 v0.26.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.62.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	go.opentelemetry.io/otel v1.32.0 // indirect
+	go.opentelemetry.io/otel/metric v1.32.0 // indirect
+	go.opentelemetry.io/otel/trace v1.32.0 // indirect
+	golang.org/x/crypto v0.32.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+)
+
+replace (
+	github.com/adrianosela/refresh => ..
+	github.com/adrianosela/refresh/integrations/oauth2 => ../integrations/oauth2
+	github.com/adrianosela/refresh/metrics/otel => ../metrics/otel
+	github.com/adrianosela/refresh/metrics/prometheus => ../metrics/prometheus
+)
@@ -0,0 +1,163 @@
+// Command mtls-server is an example HTTPS server which requires clients to present certificates
+// (mutual TLS). Both its serving certificate and its client CA bundle are (re)loaded from files
+// rotated by an external agent (e.g. cert-manager or a SPIFFE helper), such that rotations are
+// picked up without restarts. Refreshes are traced and measured with OpenTelemetry (through the
+// globally registered providers) and logged with log/slog.
+//
+// Usage:
+//
+//	mtls-server -cert server.pem -key server-key.pem -client-ca ca.pem
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/integrations/tlscert"
+	refreshotel "github.com/adrianosela/refresh/metrics/otel"
+	"github.com/adrianosela/refresh/strategies"
+)
+
+// clientCAReloadInterval is how often the client CA bundle is reloaded.
+const clientCAReloadInterval = 5 * time.Minute
+
+func main() {
+	var (
+		listen   = flag.String("listen", ":8443", "address to serve on")
+		certFile = flag.String("cert", "", "PEM encoded serving certificate (chain) file")
+		keyFile  = flag.String("key", "", "PEM encoded serving private key file")
+		caFile   = flag.String("client-ca", "", "PEM encoded bundle of CAs to verify client certificates with")
+		lead     = flag.Duration("lead", time.Hour, "how long before its NotAfter to reload the serving certificate")
+	)
+	flag.Parse()
+
+	if err := run(*listen, *certFile, *keyFile, *caFile, *lead); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(listen, certFile, keyFile, caFile string, lead time.Duration) error {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	server, stop, err := newServer(listen, certFile, keyFile, caFile, lead, logger)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServeTLS("", "") }()
+	logger.Info("serving", "listen", listen)
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	_ = server.Shutdown(shutdownCtx)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// newServer returns a server which serves with the certificate and requires client certificates
+// issued by the CAs in the given files, once they have been loaded, along with a function which
+// stops reloading them.
+func newServer(listen, certFile, keyFile, caFile string, lead time.Duration, logger *slog.Logger) (*http.Server, func(), error) {
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return nil, nil, errors.New("a certificate, key, and client CA file are required")
+	}
+
+	certs := refresh.NewRefresher(
+		refreshotel.RefreshFunc("serving_certificate", tlscert.FromFiles(certFile, keyFile)),
+		refresh.WithRefreshStrategy(tlscert.NewBeforeNotAfterStrategy(lead)),
+		refresh.WithObserver(refreshotel.MustNewObserver[*tls.Certificate]("serving_certificate")),
+		refresh.WithLogger[*tls.Certificate](logger.With("refresher", "serving_certificate")),
+		refresh.WithRetryDelay[*tls.Certificate](30*time.Second),
+	)
+
+	clientCAs := refresh.NewRefresher(
+		refreshotel.RefreshFunc("client_ca", loadCertPool(caFile)),
+		refresh.WithRefreshStrategy(strategies.NewStaticLifetimeSpent[*x509.CertPool](clientCAReloadInterval)),
+		refresh.WithObserver(refreshotel.MustNewObserver[*x509.CertPool]("client_ca")),
+		refresh.WithLogger[*x509.CertPool](logger.With("refresher", "client_ca")),
+		refresh.WithRetryDelay[*x509.CertPool](30*time.Second),
+	)
+	stop := func() {
+		certs.Stop()
+		clientCAs.Stop()
+	}
+
+	for name, wait := range map[string]func(time.Duration) error{
+		"serving certificate": certs.WaitForInitialValue,
+		"client CA bundle":    clientCAs.WaitForInitialValue,
+	} {
+		if err := wait(10 * time.Second); err != nil {
+			stop()
+			return nil, nil, fmt.Errorf("failed to load initial %s: %v", name, err)
+		}
+	}
+
+	server := &http.Server{
+		Addr: listen,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// the client CA pool can only be set per-connection through GetConfigForClient
+			GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				pool := clientCAs.GetCurrent()
+				if pool == nil {
+					return nil, errors.New("no client CA bundle available")
+				}
+				return &tls.Config{
+					MinVersion:     tls.VersionTLS12,
+					GetCertificate: tlscert.GetCertificate(certs),
+					ClientAuth:     tls.RequireAndVerifyClientCert,
+					ClientCAs:      pool.Value,
+				}, nil
+			},
+		},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "hello, %s\n", r.TLS.PeerCertificates[0].Subject.CommonName)
+		}),
+	}
+	return server, stop, nil
+}
+
+// loadCertPool returns a refresh.RefreshFunc which loads a pool of certificates from a PEM file.
+// The pool does not expire as such, so it is valid until the next reload is due (and then some).
+func loadCertPool(caFile string) refresh.RefreshFunc[*x509.CertPool] {
+	return func(context.Context) (*refresh.Refreshable[*x509.CertPool], error) {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", caFile)
+		}
+		now := time.Now()
+		return &refresh.Refreshable[*x509.CertPool]{
+			Value:     pool,
+			IssuedAt:  now,
+			ExpiresAt: now.Add(2 * clientCAReloadInterval),
+		}, nil
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issue returns a certificate for the given common name, self-signed if parent is nil,
+// along with its private key.
+func issue(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		DNSNames:     []string{cn},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// writePEM writes the given PEM blocks to a file in the given directory, returning its path.
+func writePEM(t *testing.T, dir, name string, blocks ...*pem.Block) string {
+	t.Helper()
+	var data []byte
+	for _, block := range blocks {
+		data = append(data, pem.EncodeToMemory(block)...)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func keyBlock(t *testing.T, key *ecdsa.PrivateKey) *pem.Block {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+}
+
+func TestLoadCertPool(t *testing.T) {
+	dir := t.TempDir()
+	ca, _ := issue(t, "ca", nil, nil)
+
+	pool, err := loadCertPool(writePEM(t, dir, "ca.pem", &pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))(context.Background())
+	if err != nil {
+		t.Fatalf("failed to load pool: %v", err)
+	}
+	if lifetime := pool.ExpiresAt.Sub(pool.IssuedAt); lifetime != 2*clientCAReloadInterval {
+		t.Errorf("pool valid for %s, expected %s", lifetime, 2*clientCAReloadInterval)
+	}
+
+	if _, err := loadCertPool(writePEM(t, dir, "empty.pem"))(context.Background()); err == nil {
+		t.Error("expected an error for a file without certificates")
+	}
+	if _, err := loadCertPool(filepath.Join(dir, "missing.pem"))(context.Background()); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestNewServerValidation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, _, err := newServer("127.0.0.1:0", "cert.pem", "key.pem", "", time.Hour, logger); err == nil {
+		t.Error("expected an error for a missing client CA file")
+	}
+	dir := t.TempDir()
+	if _, _, err := newServer("127.0.0.1:0", filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "ca.pem"), time.Hour, logger); err == nil {
+		t.Error("expected an error for nonexistent files")
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := issue(t, "ca", nil, nil)
+	serverCert, serverKey := issue(t, "localhost", ca, caKey)
+	clientCert, clientKey := issue(t, "client", ca, caKey)
+
+	server, stop, err := newServer(
+		"127.0.0.1:0",
+		writePEM(t, dir, "server.pem", &pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Raw}),
+		writePEM(t, dir, "server-key.pem", keyBlock(t, serverKey)),
+		writePEM(t, dir, "ca.pem", &pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}),
+		time.Hour,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer stop()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.ServeTLS(listener, "", "") }()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	get := func(certs ...tls.Certificate) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+		}}}
+		resp, err := client.Get("https://" + listener.Addr().String())
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	body, err := get(tls.Certificate{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey})
+	if err != nil {
+		t.Fatalf("request with a client certificate failed: %v", err)
+	}
+	if body != "hello, client\n" {
+		t.Errorf("unexpected response %q", body)
+	}
+	if _, err := get(); err == nil {
+		t.Error("expected a request without a client certificate to fail")
+	}
+}
@@ -0,0 +1,158 @@
+// Command multi-tenant-tokens is an example token broker which keeps an OAuth 2.0 access token
+// fresh for each of many tenants, each with its own client credentials. Tokens are acquired on
+// the first request for a tenant and kept fresh from then on, and handed out to internal callers
+// over HTTP at GET /tenants/{tenant}/token.
+//
+// Tenants are configured in a JSON file mapping tenant names to their credentials:
+//
+//	{
+//	  "acme":   {"token_url": "https://auth.acme.example/token", "client_id": "...", "client_secret": "..."},
+//	  "globex": {"token_url": "https://login.globex.example/oauth2/token", "client_id": "...", "client_secret": "..."}
+//	}
+//
+// Usage:
+//
+//	multi-tenant-tokens -tenants tenants.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/adrianosela/refresh"
+	refreshoauth2 "github.com/adrianosela/refresh/integrations/oauth2"
+	refreshprometheus "github.com/adrianosela/refresh/metrics/prometheus"
+	"github.com/adrianosela/refresh/strategies"
+)
+
+// tenant is the configuration of a tenant.
+type tenant struct {
+	TokenURL     string   `json:"token_url"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+// errUnknownTenant is returned for tenants missing from the configuration.
+var errUnknownTenant = errors.New("unknown tenant")
+
+func main() {
+	var (
+		listen      = flag.String("listen", ":8080", "address to serve on")
+		tenantsFile = flag.String("tenants", "", "JSON file with the credentials of every tenant")
+	)
+	flag.Parse()
+
+	if err := run(*listen, *tenantsFile); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(listen, tenantsFile string) error {
+	data, err := os.ReadFile(tenantsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tenants file: %v", err)
+	}
+	tenants := map[string]tenant{}
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return fmt.Errorf("failed to parse tenants file: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	handler, stop := newBroker(tenants, logger)
+	defer stop()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	server := &http.Server{Addr: listen, Handler: handler}
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+	logger.Info("serving", "listen", listen, "tenants", len(tenants))
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	_ = server.Shutdown(shutdownCtx)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// newBroker returns the handler of the token broker for the given tenants, along
+// with a function which stops refreshing their tokens.
+func newBroker(tenants map[string]tenant, logger *slog.Logger) (http.Handler, func()) {
+	collector := refreshprometheus.NewCollector(refreshprometheus.WithNamespace("token_broker"))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	tokens := refresh.NewMapRefresher(
+		func(ctx context.Context, name string) (*refresh.Refreshable[*oauth2.Token], error) {
+			t, ok := tenants[name]
+			if !ok {
+				return nil, errUnknownTenant
+			}
+			config := &clientcredentials.Config{
+				ClientID:     t.ClientID,
+				ClientSecret: t.ClientSecret,
+				TokenURL:     t.TokenURL,
+				Scopes:       t.Scopes,
+			}
+			return refreshoauth2.RefreshFunc(config.Token)(ctx)
+		},
+		refresh.WithRefresherOptions[string](
+			refresh.WithLogger[*oauth2.Token](logger),
+			refresh.WithObserver(refreshprometheus.NewObserver[*oauth2.Token](collector, "tenant_tokens")),
+			// spread the refreshes of many tenants' tokens over the second half of their lifetime
+			refresh.WithRefreshStrategy(strategies.NewRandomWithinLifetimeWindow[*oauth2.Token](0.5, 0.8)),
+			refresh.WithRetryDelay[*oauth2.Token](10*time.Second),
+		),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("GET /tenants/{tenant}/token", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		token, err := tokens.Get(ctx, r.PathValue("tenant"))
+		switch {
+		case errors.Is(err, errUnknownTenant):
+			http.Error(w, "unknown tenant", http.StatusNotFound)
+			return
+		case err != nil:
+			logger.Error("failed to get token", "tenant", r.PathValue("tenant"), "error", err)
+			http.Error(w, "token unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": token.Value.AccessToken,
+			"token_type":   token.Value.Type(),
+			"expires_at":   token.ExpiresAt,
+		})
+	})
+	return mux, tokens.Stop
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunInvalidTenantsFile(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "tenants.json")
+	if err := os.WriteFile(invalid, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tenantsFile := range []string{filepath.Join(dir, "missing.json"), invalid} {
+		if err := run("127.0.0.1:0", tenantsFile); err == nil {
+			t.Errorf("expected an error for tenants file %s", tenantsFile)
+		}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	var issued atomic.Int32
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		issued.Add(1)
+		id, _, _ := r.BasicAuth()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "token-for-" + id,
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer tokens.Close()
+
+	handler, stop := newBroker(map[string]tenant{
+		"acme":   {TokenURL: tokens.URL, ClientID: "acme-client", ClientSecret: "secret"},
+		"globex": {TokenURL: tokens.URL, ClientID: "globex-client", ClientSecret: "secret"},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer stop()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	for _, name := range []string{"acme", "globex", "acme"} {
+		rec := get("/tenants/" + name + "/token")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d for tenant %s", rec.Code, name)
+		}
+		var body struct {
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode response for tenant %s: %v", name, err)
+		}
+		if want := "token-for-" + name + "-client"; body.AccessToken != want {
+			t.Errorf("got token %q for tenant %s, expected %q", body.AccessToken, name, want)
+		}
+	}
+	if n := issued.Load(); n != 2 {
+		t.Errorf("token endpoint issued %d tokens, expected 2 (one per tenant)", n)
+	}
+
+	if rec := get("/tenants/initech/token"); rec.Code != http.StatusNotFound {
+		t.Errorf("unexpected status %d for an unknown tenant", rec.Code)
+	}
+	if rec := get("/metrics"); rec.Code != http.StatusOK {
+		t.Errorf("unexpected status %d for metrics", rec.Code)
+	}
+}
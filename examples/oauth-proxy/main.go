@@ -0,0 +1,181 @@
+// Command oauth-proxy is an example reverse proxy which authenticates every proxied request
+// with an OAuth 2.0 access token acquired with the client credentials flow. The token is kept
+// fresh by a refresher which persists it to an encrypted file, such that restarts of the proxy
+// do not request a new token, and exposes Prometheus metrics about its refreshes.
+//
+// Usage:
+//
+//	OAUTH_CLIENT_SECRET=... oauth-proxy \
+//		-upstream https://api.example.com \
+//		-token-url https://auth.example.com/oauth2/token \
+//		-client-id my-client \
+//		-cache-file /var/cache/oauth-proxy/token
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/integrations/httpclient"
+	refreshoauth2 "github.com/adrianosela/refresh/integrations/oauth2"
+	refreshprometheus "github.com/adrianosela/refresh/metrics/prometheus"
+	"github.com/adrianosela/refresh/storage"
+)
+
+func main() {
+	var (
+		listen    = flag.String("listen", ":8080", "address to serve the proxy on")
+		metrics   = flag.String("metrics-listen", ":9090", "address to serve Prometheus metrics on")
+		upstream  = flag.String("upstream", "", "URL of the upstream to proxy requests to")
+		tokenURL  = flag.String("token-url", "", "OAuth 2.0 token endpoint")
+		clientID  = flag.String("client-id", "", "OAuth 2.0 client ID (the secret is read from $OAUTH_CLIENT_SECRET)")
+		scopes    = flag.String("scopes", "", "comma-separated OAuth 2.0 scopes to request")
+		cacheFile = flag.String("cache-file", "", "file to persist the (encrypted) token to, if any")
+	)
+	flag.Parse()
+
+	if err := run(*listen, *metrics, *upstream, *tokenURL, *clientID, os.Getenv("OAUTH_CLIENT_SECRET"), *scopes, *cacheFile); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(listen, metrics, upstream, tokenURL, clientID, clientSecret, scopes, cacheFile string) error {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	p, err := newProxy(upstream, tokenURL, clientID, clientSecret, scopes, cacheFile, logger)
+	if err != nil {
+		return err
+	}
+	defer p.tokens.Stop()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	metricsServer := &http.Server{Addr: metrics, Handler: p.metrics}
+	proxyServer := &http.Server{Addr: listen, Handler: p.handler}
+
+	errs := make(chan error, 2)
+	go func() { errs <- metricsServer.ListenAndServe() }()
+	go func() { errs <- proxyServer.ListenAndServe() }()
+	logger.Info("serving", "listen", listen, "metrics", metrics, "upstream", upstream)
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	_ = proxyServer.Shutdown(shutdownCtx)
+	_ = metricsServer.Shutdown(shutdownCtx)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// proxy is the proxy's handler, along with its metrics handler and token refresher.
+type proxy struct {
+	handler http.Handler
+	metrics http.Handler
+	tokens  refresh.Refresher[*oauth2.Token]
+}
+
+// newProxy returns a proxy to the given upstream once it has acquired an initial token.
+// The caller must stop the proxy's token refresher when done with it.
+func newProxy(upstream, tokenURL, clientID, clientSecret, scopes, cacheFile string, logger *slog.Logger) (*proxy, error) {
+	upstreamURL, err := url.Parse(upstream)
+	if err != nil || upstreamURL.Host == "" {
+		return nil, fmt.Errorf("invalid upstream %q", upstream)
+	}
+	if tokenURL == "" || clientID == "" || clientSecret == "" {
+		return nil, errors.New("a token URL, client ID, and client secret are required")
+	}
+
+	collector := refreshprometheus.NewCollector(refreshprometheus.WithNamespace("oauth_proxy"))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	opts := []refresh.Option[*oauth2.Token]{
+		refresh.WithLogger[*oauth2.Token](logger),
+		refresh.WithObserver(refreshprometheus.NewObserver[*oauth2.Token](collector, "upstream_token")),
+		refresh.WithRetryDelay[*oauth2.Token](10 * time.Second),
+	}
+	if cacheFile != "" {
+		tokenStorage, err := newEncryptedFileStorage(cacheFile, clientSecret)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, refresh.WithStorage(tokenStorage))
+	}
+
+	config := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     tokenURL,
+	}
+	if scopes != "" {
+		config.Scopes = strings.Split(scopes, ",")
+	}
+	tokens := refreshoauth2.NewClientCredentialsRefresher(config, opts...)
+	if err := tokens.WaitForInitialValue(30 * time.Second); err != nil {
+		tokens.Stop()
+		return nil, fmt.Errorf("failed to acquire initial token: %v", err)
+	}
+
+	reverseProxy := httputil.NewSingleHostReverseProxy(upstreamURL)
+	reverseProxy.Transport = httpclient.NewTransport(
+		tokens,
+		func(req *http.Request, token *oauth2.Token) { token.SetAuthHeader(req) },
+		httpclient.WithRetryOnUnauthorized[*oauth2.Token](),
+	)
+	return &proxy{
+		handler: reverseProxy,
+		metrics: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		tokens:  tokens,
+	}, nil
+}
+
+// newEncryptedFileStorage returns a refresh.Storage which persists tokens to the given file,
+// encrypted with a key derived from the given secret. A missing file fails the storage read,
+// in which case the refresher simply acquires a new token.
+func newEncryptedFileStorage(path, secret string) (refresh.Storage[*oauth2.Token], error) {
+	cipher, err := storage.NewAESGCMCipherFromSecret(storage.NewHKDFSHA256(), []byte(secret), []byte(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %v", err)
+	}
+	backend := storage.BackendFromFunctions(
+		func(context.Context) ([]byte, error) { return os.ReadFile(path) },
+		func(_ context.Context, data []byte) error {
+			tmp := path + ".tmp"
+			if err := os.WriteFile(tmp, data, 0o600); err != nil {
+				return err
+			}
+			return os.Rename(tmp, path)
+		},
+	)
+	return storage.New(
+		backend,
+		storage.NewJSONCodec[*oauth2.Token](),
+		storage.WithCipher[*oauth2.Token](cipher),
+		storage.WithSkipUnchangedWrites[*oauth2.Token](),
+	), nil
+}
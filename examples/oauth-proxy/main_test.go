@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTokenServer returns an OAuth 2.0 token endpoint which issues numbered tokens,
+// along with the number of tokens it has issued.
+func newTokenServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"access_token":"token-`+strconv.Itoa(int(n))+`","token_type":"Bearer","expires_in":3600}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func TestNewProxyValidation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for name, args := range map[string][]string{
+		"missing upstream":  {"", "https://auth.example.com/token", "id", "secret"},
+		"relative upstream": {"/api", "https://auth.example.com/token", "id", "secret"},
+		"missing token URL": {"https://api.example.com", "", "id", "secret"},
+		"missing client ID": {"https://api.example.com", "https://auth.example.com/token", "", "secret"},
+		"missing secret":    {"https://api.example.com", "https://auth.example.com/token", "id", ""},
+	} {
+		if _, err := newProxy(args[0], args[1], args[2], args[3], "", "", logger); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestProxy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tokens, issued := newTokenServer(t)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer upstream.Close()
+	cacheFile := filepath.Join(t.TempDir(), "token")
+
+	get := func(p *proxy) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		p.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/resource", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d", rec.Code)
+		}
+		return rec.Body.String()
+	}
+
+	p, err := newProxy(upstream.URL, tokens.URL, "id", "secret", "a,b", cacheFile, logger)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	if got := get(p); got != "Bearer token-1" {
+		t.Errorf("upstream received authorization %q, expected %q", got, "Bearer token-1")
+	}
+
+	rec := httptest.NewRecorder()
+	p.metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "oauth_proxy_") {
+		t.Errorf("metrics do not include the proxy's refreshes:\n%s", rec.Body.String())
+	}
+	if err := p.tokens.StopAndWait(context.Background()); err != nil { // waits for the cache write
+		t.Fatal(err)
+	}
+
+	// a restarted proxy loads the cached token instead of requesting a new one
+	p, err = newProxy(upstream.URL, tokens.URL, "id", "secret", "a,b", cacheFile, logger)
+	if err != nil {
+		t.Fatalf("failed to create restarted proxy: %v", err)
+	}
+	defer p.tokens.Stop()
+	if got := get(p); got != "Bearer token-1" {
+		t.Errorf("upstream received authorization %q after restart, expected %q", got, "Bearer token-1")
+	}
+	if n := issued.Load(); n != 1 {
+		t.Errorf("token endpoint issued %d tokens, expected 1", n)
+	}
+}
+
+func TestEncryptedFileStorageWrongSecret(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tokens, issued := newTokenServer(t)
+	cacheFile := filepath.Join(t.TempDir(), "token")
+
+	p, err := newProxy("https://api.example.com", tokens.URL, "id", "secret", "", cacheFile, logger)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	if err := p.tokens.StopAndWait(context.Background()); err != nil { // waits for the cache write
+		t.Fatal(err)
+	}
+
+	// a token cached with another secret cannot be decrypted, so a new one is requested
+	p, err = newProxy("https://api.example.com", tokens.URL, "id", "other-secret", "", cacheFile, logger)
+	if err != nil {
+		t.Fatalf("failed to create proxy with another secret: %v", err)
+	}
+	defer p.tokens.Stop()
+	if n := issued.Load(); n != 2 {
+		t.Errorf("token endpoint issued %d tokens, expected 2", n)
+	}
+}
@@ -0,0 +1,66 @@
+package refresh
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithOnExpire is the refresher Option to set a callback function to be fired the moment the
+// current value expires without having been replaced, e.g. because refreshes keep failing.
+// Expiry is tracked independently of the refresh schedule. Note that a Manager reports expired
+// values as unhealthy regardless of this option.
+func WithOnExpire[T any](onExpire func(*Refreshable[T])) Option[T] {
+	return func(r *refresher[T]) { r.onExpire = onExpire }
+}
+
+// newExpiryTimer returns a timer which fires when the current value expires, or nil if
+// there is neither a callback to fire nor an events buffer (in which case expiry is not tracked).
+func (r *refresher[T]) newExpiryTimer() *time.Timer {
+	if r.onExpire == nil && r.eventBufferSize == 0 {
+		return nil
+	}
+	t := time.NewTimer(0)
+	r.resetExpiryTimer(t)
+	return t
+}
+
+// resetExpiryTimer resets the given expiry timer (if any) to fire when the current value expires.
+func (r *refresher[T]) resetExpiryTimer(t *time.Timer) {
+	if t == nil {
+		return
+	}
+	current := r.getCurrent()
+	if current == nil {
+		if !t.Stop() {
+			select {
+			case <-t.C:
+			default:
+			}
+		}
+		return
+	}
+	resetTimer(t, time.Until(current.ExpiresAt))
+}
+
+// expiryTimerC returns the channel of the given expiry timer, or nil (which blocks forever) if there is none.
+func expiryTimerC(t *time.Timer) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.C
+}
+
+// expire fires the expiry callback if the current value is expired, returning false
+// if it is not (i.e. it was replaced), in which case the expiry timer must be reset.
+func (r *refresher[T]) expire() bool {
+	current := r.getCurrent()
+	if current == nil || time.Now().Before(current.ExpiresAt) {
+		return false
+	}
+	r.log(slog.LevelWarn, "value expired without replacement", "expires_at", current.ExpiresAt)
+	if r.onExpire != nil {
+		r.emit(func() { r.onExpire(current) })
+	}
+	r.publish(Event[T]{Kind: EventExpired, Refreshable: current})
+	return true
+}
@@ -0,0 +1,63 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// WithFallbackRefreshFunc is the refresher Option to add RefreshFuncs which are tried in order
+// whenever the refresher's RefreshFunc fails (or returns an invalid value), e.g. the token
+// endpoint of a secondary region, or a long-lived bootstrap credential. Fallbacks are tried
+// within the same refresh attempt, bypassing any middleware, and the refresh fails only if
+// all of them fail too. The Source of EventRefreshSucceeded events reports which one succeeded.
+//
+// This Option can be provided multiple times to add more fallbacks.
+func WithFallbackRefreshFunc[T any](fallbacks ...RefreshFunc[T]) Option[T] {
+	return func(r *refresher[T]) { r.fallbacks = append(r.fallbacks, fallbacks...) }
+}
+
+// reissue acquires a new value with the given RefreshFuncWithPrevious, handling nil
+// values as per the refresher's NilRefreshablePolicy and validating the new value.
+func (r *refresher[T]) reissue(
+	ctx context.Context,
+	refreshFunc RefreshFuncWithPrevious[T],
+	current *Refreshable[T],
+) (*Refreshable[T], error) {
+	reissued, err := refreshFunc(ctx, current)
+	if err == nil && reissued == nil {
+		if reissued, err = r.nilRefreshablePolicy(current); err == nil && reissued == nil {
+			err = ErrNilRefreshable
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.validate(reissued); err != nil {
+		return nil, err
+	}
+	return reissued, nil
+}
+
+// fallback tries the refresher's fallback RefreshFuncs in order, after the primary one
+// failed with the given error, returning the first new value along with the (1-based)
+// index of the fallback which acquired it.
+func (r *refresher[T]) fallback(ctx context.Context, current *Refreshable[T], err error) (*Refreshable[T], int, error) {
+	errs := []error{err}
+	for i, refreshFunc := range r.fallbacks {
+		if ctx.Err() != nil {
+			break
+		}
+		source := i + 1
+		r.log(slog.LevelWarn, "refresh source failed, trying fallback", "error", errs[len(errs)-1], "source", source)
+		reissued, err := r.reissue(ctx, func(ctx context.Context, _ *Refreshable[T]) (*Refreshable[T], error) {
+			return refreshFunc(ctx)
+		}, current)
+		if err == nil {
+			return reissued, source, nil
+		}
+		errs = append(errs, fmt.Errorf("fallback %d: %v", source, err))
+	}
+	return nil, 0, errors.Join(errs...)
+}
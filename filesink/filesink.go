@@ -0,0 +1,234 @@
+// Package filesink provides a sink which renders refreshed values into files on disk,
+// for consumers (e.g. nginx, envoy) which read credentials from files and hot-reload
+// them on change.
+//
+// All the files rendered from a value are updated atomically with respect to each other.
+// They are written into a new hidden directory, and a "..data" symbolic link pointing to
+// it is then atomically swapped. The files themselves are symbolic links into "..data",
+// such that consumers never observe a mix of files rendered from different values.
+//
+// A Sink is added to a refresher with refresh.WithSink, which reports failures to write
+// files or to run post-write hooks as refresh.EventSinkWrite events:
+//
+//	sink := filesink.New("/etc/nginx/certs", mapper, filesink.WithPostWriteHook(filesink.Exec("nginx", "-s", "reload")))
+//
+//	r := refresh.NewRefresher(refreshFunc, refresh.WithSink[*tls.Certificate](sink))
+package filesink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+const (
+	dataLinkName    = "..data"
+	dataDirPrefix   = "..data_"
+	tmpLinkName     = "..data_tmp"
+	defaultFileMode = fs.FileMode(0600)
+)
+
+// Mapper renders a Refreshable into a set of files, keyed by file name.
+// File names must not contain path separators or start with "..".
+type Mapper[T any] func(*refresh.Refreshable[T]) (map[string][]byte, error)
+
+// Option represents a Sink configuration option.
+type Option func(*config)
+
+// config is the configuration of a Sink.
+type config struct {
+	fileMode       fs.FileMode
+	onWriteSuccess func([]string)
+	hooks          []Hook
+	hookTimeout    time.Duration
+}
+
+// WithFileMode is the Sink Option to override the default (0600) mode of written files.
+func WithFileMode(fileMode fs.FileMode) Option {
+	return func(c *config) { c.fileMode = fileMode }
+}
+
+// WithOnWriteSuccess is the Sink Option to set a callback function to be fired
+// after a successful writing of files, with the paths of all written files.
+func WithOnWriteSuccess(onWriteSuccess func([]string)) Option {
+	return func(c *config) { c.onWriteSuccess = onWriteSuccess }
+}
+
+// Sink renders Refreshables into files in a directory.
+type Sink[T any] struct {
+	sync.Mutex
+
+	dir    string
+	mapper Mapper[T]
+	config config
+}
+
+// New returns a Sink which renders Refreshables into files in the given directory
+// with the given Mapper. The directory is created if it does not exist.
+func New[T any](dir string, mapper Mapper[T], opts ...Option) *Sink[T] {
+	s := &Sink[T]{
+		dir:    dir,
+		mapper: mapper,
+		config: config{
+			fileMode:       defaultFileMode,
+			onWriteSuccess: func([]string) { /* NOOP */ },
+			hookTimeout:    DefaultHookTimeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(&s.config)
+	}
+	return s
+}
+
+// Write writes the files rendered from the given Refreshable and then runs the Sink's
+// post-write hooks, such that the Sink implements refresh.Sink. Hooks are not run if
+// the files could not be written, and failed hooks do not prevent the others from running.
+func (s *Sink[T]) Write(ctx context.Context, refreshable *refresh.Refreshable[T]) error {
+	paths, err := s.WriteFiles(refreshable)
+	if err != nil {
+		return err
+	}
+	s.config.onWriteSuccess(paths)
+	return s.runHooks(ctx, paths)
+}
+
+// WriteFiles renders the given Refreshable and atomically replaces the files in the
+// Sink's directory with the result, removing files which are no longer rendered.
+// It returns the paths of all written files.
+func (s *Sink[T]) WriteFiles(refreshable *refresh.Refreshable[T]) ([]string, error) {
+	files, err := s.mapper(refreshable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render files: %v", err)
+	}
+	for name := range files {
+		if name == "" || strings.ContainsRune(name, filepath.Separator) || strings.HasPrefix(name, "..") {
+			return nil, fmt.Errorf("invalid file name %q", name)
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	oldDataDir, _ := os.Readlink(filepath.Join(s.dir, dataLinkName))
+
+	newDataDir, err := s.writeDataDir(files)
+	if err != nil {
+		return nil, err
+	}
+
+	// atomically point the data link to the new data directory
+	tmpLink := filepath.Join(s.dir, tmpLinkName)
+	_ = os.Remove(tmpLink)
+	if err := os.Symlink(filepath.Base(newDataDir), tmpLink); err != nil {
+		_ = os.RemoveAll(newDataDir)
+		return nil, fmt.Errorf("failed to create data link: %v", err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(s.dir, dataLinkName)); err != nil {
+		_ = os.RemoveAll(newDataDir)
+		return nil, fmt.Errorf("failed to swap data link: %v", err)
+	}
+
+	paths := make([]string, 0, len(files))
+	for name := range files {
+		path := filepath.Join(s.dir, name)
+		if err := ensureSymlink(filepath.Join(dataLinkName, name), path); err != nil {
+			return nil, fmt.Errorf("failed to link file %q: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	if err := s.removeStaleLinks(files); err != nil {
+		return nil, err
+	}
+	if oldDataDir != "" && oldDataDir != filepath.Base(newDataDir) {
+		_ = os.RemoveAll(filepath.Join(s.dir, oldDataDir))
+	}
+	return paths, nil
+}
+
+// removeStaleLinks removes the links to files which are no longer rendered, which
+// would otherwise be left dangling once the old data directory is removed.
+func (s *Sink[T]) removeStaleLinks(files map[string][]byte) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list directory: %v", err)
+	}
+	var errs []error
+	for _, entry := range entries {
+		name := entry.Name()
+		if _, ok := files[name]; ok || entry.Type()&fs.ModeSymlink == 0 || strings.HasPrefix(name, "..") {
+			continue
+		}
+		path := filepath.Join(s.dir, name)
+		// only links created by the Sink are removed, not unrelated files in the directory
+		if target, err := os.Readlink(path); err != nil || target != filepath.Join(dataLinkName, name) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove stale file %q: %v", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeDataDir writes the given files into a new data directory and returns its path.
+func (s *Sink[T]) writeDataDir(files map[string][]byte) (string, error) {
+	dataDir, err := os.MkdirTemp(s.dir, dataDirPrefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to create data directory: %v", err)
+	}
+	if err := os.Chmod(dataDir, 0755); err != nil {
+		_ = os.RemoveAll(dataDir)
+		return "", fmt.Errorf("failed to set data directory mode: %v", err)
+	}
+	for name, contents := range files {
+		if err := writeFileSync(filepath.Join(dataDir, name), contents, s.config.fileMode); err != nil {
+			_ = os.RemoveAll(dataDir)
+			return "", fmt.Errorf("failed to write file %q: %v", name, err)
+		}
+	}
+	return dataDir, nil
+}
+
+// writeFileSync writes a file and flushes it to disk.
+func writeFileSync(path string, contents []byte, mode fs.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(contents); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ensureSymlink makes sure that path is a symbolic link to target.
+func ensureSymlink(target, path string) error {
+	if existing, err := os.Readlink(path); err == nil && existing == target {
+		return nil
+	}
+	tmpPath := path + ".tmp"
+	_ = os.Remove(tmpPath)
+	if err := os.Symlink(target, tmpPath); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,105 @@
+package filesink
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// files is a Mapper which renders every entry of a map into a file.
+func files(refreshable *refresh.Refreshable[map[string]string]) (map[string][]byte, error) {
+	rendered := make(map[string][]byte, len(refreshable.Value))
+	for name, contents := range refreshable.Value {
+		rendered[name] = []byte(contents)
+	}
+	return rendered, nil
+}
+
+// refreshable returns a Refreshable with the given files.
+func refreshable(value map[string]string) *refresh.Refreshable[map[string]string] {
+	now := time.Now()
+	return &refresh.Refreshable[map[string]string]{Value: value, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+}
+
+func TestWriteRemovesFilesNoLongerRendered(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "unrelated"), []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sink := New(dir, files)
+
+	if err := sink.Write(context.Background(), refreshable(map[string]string{"cert.pem": "1", "ca.pem": "1"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(context.Background(), refreshable(map[string]string{"cert.pem": "2"})); err != nil {
+		t.Fatal(err)
+	}
+
+	if contents, err := os.ReadFile(filepath.Join(dir, "cert.pem")); err != nil || string(contents) != "2" {
+		t.Errorf("cert.pem contains %q (%v), expected the second value", contents, err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "ca.pem")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ca.pem was not removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "unrelated")); err != nil {
+		t.Errorf("unrelated file was removed: %v", err)
+	}
+}
+
+func TestExecHookTimeout(t *testing.T) {
+	if _, err := os.Stat("/bin/sleep"); err != nil {
+		t.Skip("sleep is not available")
+	}
+	sink := New(t.TempDir(), files,
+		WithPostWriteHook(Exec("/bin/sleep", "10")),
+		WithHookTimeout(50*time.Millisecond))
+
+	began := time.Now()
+	if err := sink.Write(context.Background(), refreshable(map[string]string{"token": "1"})); err == nil {
+		t.Fatal("hook which outlived its time budget did not fail")
+	}
+	if elapsed := time.Since(began); elapsed > 5*time.Second {
+		t.Errorf("hook was not killed after its time budget, took %s", elapsed)
+	}
+}
+
+func TestHookFailureReportedAsEvent(t *testing.T) {
+	hookErr := errors.New("reload failed")
+	sink := New(t.TempDir(), files,
+		WithPostWriteHook(func(context.Context, []string) error { return hookErr }))
+
+	r := refresh.NewRefresher(
+		func(context.Context) (*refresh.Refreshable[map[string]string], error) {
+			return refreshable(map[string]string{"token": "1"}), nil
+		},
+		refresh.WithSink[map[string]string](sink),
+		refresh.WithLazyStart[map[string]string](),
+	)
+	events := r.Events()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Kind != refresh.EventSinkWrite {
+				continue
+			}
+			if event.Err == nil || !strings.Contains(event.Err.Error(), hookErr.Error()) {
+				t.Errorf("sink write event has error %v, expected the hook's failure", event.Err)
+			}
+			r.Stop()
+			return
+		case <-timeout:
+			t.Fatal("no sink write event received")
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package filesink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHookTimeout is the default time budget for running a post-write Hook.
+const DefaultHookTimeout = 30 * time.Second
+
+// Hook is a function run after files are written, with the paths of all written files.
+// Hooks are typically used to notify external processes that their files changed. The
+// given context is done once the Hook's time budget (see WithHookTimeout) is spent.
+type Hook func(ctx context.Context, paths []string) error
+
+// WithPostWriteHook is the Sink Option to add a Hook to be run after every successful
+// writing of files. Hooks are run sequentially in the order in which they were added.
+func WithPostWriteHook(hook Hook) Option {
+	return func(c *config) { c.hooks = append(c.hooks, hook) }
+}
+
+// WithHookTimeout is the Sink Option to override the default time budget
+// (DefaultHookTimeout) for running each post-write Hook.
+func WithHookTimeout(timeout time.Duration) Option {
+	return func(c *config) { c.hookTimeout = timeout }
+}
+
+// SignalPID returns a Hook which sends the given signal to the process with the given PID.
+func SignalPID(pid int, sig os.Signal) Hook {
+	return func(context.Context, []string) error {
+		return signal(pid, sig)
+	}
+}
+
+// SignalPIDFile returns a Hook which sends the given signal to the process with the PID
+// in the given file. The file is read every time the Hook runs, such that restarts of
+// the target process are tolerated.
+func SignalPIDFile(pidFile string, sig os.Signal) Hook {
+	return func(context.Context, []string) error {
+		contents, err := os.ReadFile(pidFile)
+		if err != nil {
+			return fmt.Errorf("failed to read pid file: %v", err)
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+		if err != nil {
+			return fmt.Errorf("failed to parse pid file %q: %v", pidFile, err)
+		}
+		return signal(pid, sig)
+	}
+}
+
+// Exec returns a Hook which runs the given command (e.g. "nginx", "-s", "reload")
+// and waits for it to complete. A non-zero exit status is reported as a failure
+// along with the command's output. The command is killed if it does not complete
+// within the Hook's time budget.
+func Exec(name string, args ...string) Hook {
+	return func(ctx context.Context, _ []string) error {
+		var output bytes.Buffer
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run %q: %v: %s", cmd.String(), err, bytes.TrimSpace(output.Bytes()))
+		}
+		return nil
+	}
+}
+
+// signal sends a signal to a process.
+func signal(pid int, sig os.Signal) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find process %d: %v", pid, err)
+	}
+	if err := process.Signal(sig); err != nil {
+		return fmt.Errorf("failed to signal process %d: %v", pid, err)
+	}
+	return nil
+}
+
+// runHooks runs the Sink's post-write hooks, each within its time budget, and returns their failures.
+func (s *Sink[T]) runHooks(ctx context.Context, paths []string) error {
+	var errs []error
+	for i, hook := range s.config.hooks {
+		if err := s.runHook(ctx, hook, paths); err != nil {
+			errs = append(errs, fmt.Errorf("post-write hook %d failed: %v", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runHook runs a post-write hook within its time budget.
+func (s *Sink[T]) runHook(ctx context.Context, hook Hook, paths []string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.hookTimeout)
+	defer cancel()
+	return hook(ctx, paths)
+}
@@ -1,3 +1,5 @@
 module github.com/adrianosela/refresh
 
 go 1.22
+
+require golang.org/x/crypto v0.32.0
@@ -0,0 +1,39 @@
+package refresh
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetryAfterError is an error which a RefreshFunc may return to convey when a failed refresh
+// should be retried, e.g. as per the Retry-After header of a rate limited response. The refresher
+// retries failed background refreshes after the given delay rather than after its retry delay.
+type RetryAfterError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+// Error returns the error's message.
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.RetryAfter)
+}
+
+// Unwrap returns the underlying error.
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter wraps an error in a *RetryAfterError with the given delay.
+func RetryAfter(err error, retryAfter time.Duration) error {
+	return &RetryAfterError{RetryAfter: retryAfter, Err: err}
+}
+
+// retryDelayFor returns how long to wait before retrying a refresh which failed with the given error.
+func (r *refresher[T]) retryDelayFor(err error) time.Duration {
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.RetryAfter > 0 {
+		return retryAfter.RetryAfter
+	}
+	return r.retryDelay
+}
@@ -0,0 +1,43 @@
+package refresh
+
+import "time"
+
+// WithHistory is the refresher Option to keep the last n values (including the current one)
+// for GetHistory, e.g. for verifiers which must accept material issued shortly before a
+// rotation, such as tokens signed with a recently rotated signing key. The default is 1,
+// i.e. only the current value.
+func WithHistory[T any](n int) Option[T] {
+	return func(r *refresher[T]) {
+		if n < 1 {
+			n = 1
+		}
+		r.historySize = n
+	}
+}
+
+// GetHistory returns the last values of the refresher which are not expired, newest first.
+func (r *refresher[T]) GetHistory() []*Refreshable[T] {
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+
+	now := time.Now()
+	history := make([]*Refreshable[T], 0, len(r.history))
+	for _, refreshable := range r.history {
+		if !isExpired(refreshable, now) {
+			history = append(history, refreshable)
+		}
+	}
+	return history
+}
+
+// recordHistory adds a new value to the refresher's history,
+// dropping the oldest value if the history is full.
+func (r *refresher[T]) recordHistory(newValue *Refreshable[T]) {
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+
+	if len(r.history) >= r.historySize {
+		r.history = r.history[:r.historySize-1]
+	}
+	r.history = append([]*Refreshable[T]{newValue}, r.history...)
+}
@@ -0,0 +1,187 @@
+package refresh
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// WithInitializationDeadline is the refresher Option to bound the entire initialization
+// sequence (i.e. reading from storage and acquiring an initial value) with a single time
+// budget. If the budget is exhausted, initialization is reported as failed with an
+// *InitializationDeadlineError detailing where the time went, and the refresher carries
+// on trying to acquire a value in the background.
+func WithInitializationDeadline[T any](deadline time.Duration) Option[T] {
+	return func(r *refresher[T]) { r.initializationDeadline = deadline }
+}
+
+// WithInitialRetry is the refresher Option to retry the initial refresh up to the given number
+// of attempts (in total), waiting the given backoff after the first failed attempt and doubling
+// it after every subsequent one, before reporting initialization as failed. This rides out
+// transient failures at startup (e.g. during rolling deploys) which would otherwise leave the
+// refresher without a value until the retry delay elapses. Every failed attempt is reported to
+// the refresh-failure event handler, and retries are bounded by any initialization deadline.
+func WithInitialRetry[T any](attempts int, backoff time.Duration) Option[T] {
+	return func(r *refresher[T]) {
+		r.initialRefreshAttempts = attempts
+		r.initialRefreshBackoff = backoff
+	}
+}
+
+// InitializationStage represents a stage of a refresher's initialization sequence,
+// which is incomplete if it was interrupted by the initialization deadline.
+type InitializationStage struct {
+	Name     string
+	Duration time.Duration
+	Complete bool
+}
+
+// InitializationDeadlineError is the error reported when
+// a refresher's initialization deadline is exceeded.
+type InitializationDeadlineError struct {
+	Deadline time.Duration
+	Stages   []InitializationStage
+}
+
+// Error returns the error's message, including a breakdown of time spent per stage.
+func (e *InitializationDeadlineError) Error() string {
+	stages := make([]string, 0, len(e.Stages))
+	for _, stage := range e.Stages {
+		status := ""
+		if !stage.Complete {
+			status = " (incomplete)"
+		}
+		stages = append(stages, fmt.Sprintf("%s: %s%s", stage.Name, stage.Duration, status))
+	}
+	return fmt.Sprintf("initialization deadline of %s exceeded [%s]", e.Deadline, strings.Join(stages, ", "))
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *InitializationDeadlineError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// initialization keeps track of the stages of a refresher's initialization sequence.
+type initialization struct {
+	ctx    context.Context
+	stages []InitializationStage
+}
+
+// stage records a stage which began at the given time and ends now. The stage
+// is considered incomplete if it was interrupted by the initialization deadline.
+func (i *initialization) stage(name string, began time.Time) {
+	i.stages = append(i.stages, InitializationStage{
+		Name:     name,
+		Duration: time.Since(began),
+		Complete: i.ctx.Err() == nil,
+	})
+}
+
+// initialize runs the refresher's initialization sequence, returning
+// a non-nil error if no initial value could be acquired.
+func (r *refresher[T]) initialize(ctx context.Context) error {
+	init := &initialization{ctx: ctx}
+	if r.initializationDeadline > 0 {
+		var cancel context.CancelFunc
+		init.ctx, cancel = context.WithTimeout(ctx, r.initializationDeadline)
+		defer cancel()
+	}
+
+	// try retrieve from storage first
+	defer r.storageReadDone()
+	var metadata *Metadata
+	if r.storage != nil {
+		metadata = r.restoreMetadata(init.ctx)
+
+		began := time.Now()
+		valueFromStorage, err := r.load(init.ctx)
+		if err == nil {
+			if err = r.validate(valueFromStorage); err != nil {
+				valueFromStorage = nil
+			}
+		}
+		init.stage("storage read", began)
+		r.observeStorageRead(StorageObservation[T]{Refreshable: valueFromStorage, Duration: time.Since(began), Err: err})
+		r.publish(Event[T]{Kind: EventStorageRead, Refreshable: valueFromStorage, Duration: time.Since(began), Err: err})
+		if err != nil {
+			r.log(slog.LevelWarn, "storage read failed", "error", err)
+			r.emit(func() { r.onStorageReadFailure(err) })
+		} else {
+			refreshAt := r.getRefreshAt(valueFromStorage)
+
+			// if the value is still fresh, we use it
+			if r.isFreshFromStorage(valueFromStorage, refreshAt) {
+				refreshAt = r.deferForBlackouts(valueFromStorage, r.clampRefreshAt(refreshAt, valueFromStorage.IssuedAt))
+				r.log(slog.LevelInfo, "loaded fresh value from storage",
+					append(r.valueAttrs(valueFromStorage), "next_refresh_at", refreshAt)...)
+				r.emit(func() { r.onStorageReadSuccess(valueFromStorage, refreshAt) })
+				r.emit(func() { r.onStorageHit(valueFromStorage, StorageHitFresh) })
+				r.updateValue(valueFromStorage, refreshAt)
+				return nil
+			}
+			r.log(slog.LevelInfo, "value from storage is due for refresh", "expires_at", valueFromStorage.ExpiresAt)
+			r.emit(func() { r.onStorageReadSuccess(valueFromStorage, time.Now()) })
+			r.emit(func() { r.onStorageHit(valueFromStorage, StorageHitStale) })
+		}
+	}
+
+	r.storageReadDone()
+
+	// if the refresher has no value at this point, we need a fresh one.
+	if r.getCurrent() != nil {
+		return nil
+	}
+	if err := init.ctx.Err(); err != nil {
+		return r.initializationErr(ctx, init, err)
+	}
+	if metadata != nil {
+		began := time.Now()
+		err := r.resumeBackoff(init.ctx, metadata)
+		init.stage("backoff", began)
+		if err != nil {
+			return r.initializationErr(ctx, init, err)
+		}
+	}
+	began := time.Now()
+	err := r.initialRefresh(init.ctx)
+	init.stage("initial refresh", began)
+	if err != nil {
+		return r.initializationErr(ctx, init, err)
+	}
+	r.log(slog.LevelInfo, "acquired initial value", "duration", time.Since(began))
+	return nil
+}
+
+// initialRefresh acquires an initial value, retrying failed attempts
+// as per the refresher's initial retry policy.
+func (r *refresher[T]) initialRefresh(ctx context.Context) error {
+	backoff := r.initialRefreshBackoff
+	for attempt := 1; ; attempt++ {
+		_, err := r.refreshShared().wait(ctx)
+		if err == nil || attempt >= r.initialRefreshAttempts || ctx.Err() != nil {
+			return err
+		}
+		r.log(slog.LevelWarn, "initial refresh failed, retrying", "error", err, "attempt", attempt, "retry_in", backoff)
+		if !sleep(ctx, backoff) {
+			return err
+		}
+		backoff *= 2
+		if r.getCurrent() != nil {
+			return nil // e.g. acquired by an explicit refresh meanwhile
+		}
+	}
+}
+
+// initializationErr returns the error to report for a failed initialization.
+func (r *refresher[T]) initializationErr(ctx context.Context, init *initialization, err error) error {
+	switch {
+	case ctx.Err() != nil:
+		err = r.wrapErr(ErrStopped, context.Cause(ctx))
+	case init.ctx.Err() != nil:
+		err = r.wrapErr(ErrInitTimeout, &InitializationDeadlineError{Deadline: r.initializationDeadline, Stages: init.stages})
+	}
+	r.log(slog.LevelError, "failed to acquire initial value", "error", err)
+	return err
+}
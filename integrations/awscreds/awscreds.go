@@ -0,0 +1,75 @@
+// Package awscreds provides an AWS SDK v2 aws.CredentialsProvider backed by a refresh.Refresher,
+// such that credentials (e.g. STS-assumed-role or custom-broker credentials) are proactively
+// refreshed in the background with this library's strategies, rather than lazily on expiry.
+package awscreds
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/adrianosela/refresh"
+)
+
+// DefaultLifetime is the lifetime assumed for credentials which cannot expire.
+const DefaultLifetime = time.Hour
+
+// credentialsProvider is an aws.CredentialsProvider backed by a refresh.Refresher.
+type credentialsProvider struct {
+	refresher refresh.Refresher[aws.Credentials]
+}
+
+// CredentialsProvider returns an aws.CredentialsProvider which returns the current
+// credentials of the given refresh.Refresher. If the current credentials are expired,
+// an immediate refresh is triggered and waited on.
+func CredentialsProvider(r refresh.Refresher[aws.Credentials]) aws.CredentialsProvider {
+	return &credentialsProvider{refresher: r}
+}
+
+// Retrieve returns the current credentials.
+func (p *credentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	current, err := p.refresher.GetFresh(ctx)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to get fresh credentials: %v", err)
+	}
+	if current == nil {
+		return aws.Credentials{}, fmt.Errorf("refresher returned no credentials")
+	}
+	creds := current.Value
+	creds.CanExpire = true
+	creds.Expires = current.ExpiresAt
+	return creds, nil
+}
+
+// RefreshFunc returns a refresh.RefreshFunc which acquires new credentials from the given
+// aws.CredentialsProvider (e.g. an stscreds.AssumeRoleProvider), populating the resulting
+// Refreshable's ExpiresAt from the credentials' expiry.
+//
+// The given provider must retrieve new credentials every time it is called, so it
+// must not be wrapped in an aws.CredentialsCache.
+func RefreshFunc(provider aws.CredentialsProvider) refresh.RefreshFunc[aws.Credentials] {
+	return func(ctx context.Context) (*refresh.Refreshable[aws.Credentials], error) {
+		creds, err := provider.Retrieve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		expiresAt := creds.Expires
+		if !creds.CanExpire || expiresAt.IsZero() {
+			expiresAt = now.Add(DefaultLifetime)
+		}
+		return &refresh.Refreshable[aws.Credentials]{
+			Value:     creds,
+			IssuedAt:  now,
+			ExpiresAt: expiresAt,
+		}, nil
+	}
+}
+
+// NewRefresher returns a refresh.Refresher which keeps credentials
+// acquired from the given aws.CredentialsProvider fresh.
+func NewRefresher(provider aws.CredentialsProvider, opts ...refresh.Option[aws.Credentials]) refresh.Refresher[aws.Credentials] {
+	return refresh.NewRefresher(RefreshFunc(provider), opts...)
+}
@@ -0,0 +1,12 @@
+module github.com/adrianosela/refresh/integrations/awscreds
+
+go 1.22
+
+require (
+	github.com/adrianosela/refresh v0.0.0-00010101000000-000000000000
+	github.com/aws/aws-sdk-go-v2 v1.36.3
+)
+
+require github.com/aws/smithy-go v1.22.2 // indirect
+
+replace github.com/adrianosela/refresh => ../..
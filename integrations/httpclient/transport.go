@@ -0,0 +1,180 @@
+// Package httpclient provides an http.RoundTripper which decorates outgoing
+// requests with the fresh value of a refresh.Refresher (e.g. a bearer token).
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// DefaultUnauthorizedRefreshInterval is the default minimum interval between
+// refreshes forced by responses with status 401 Unauthorized.
+const DefaultUnauthorizedRefreshInterval = 10 * time.Second
+
+// errRefreshRateLimited is returned when a refresh may not be forced yet.
+var errRefreshRateLimited = errors.New("refresh rate limited")
+
+// DecorateFunc decorates an outgoing request with a value, e.g. by setting
+// its Authorization header. The request is a clone which may be modified.
+type DecorateFunc[T any] func(*http.Request, T)
+
+// Option represents a Transport configuration option.
+type Option[T any] func(*Transport[T])
+
+// WithBase is the Transport Option to set the underlying http.RoundTripper
+// which carries out requests. The default is http.DefaultTransport.
+func WithBase[T any](base http.RoundTripper) Option[T] {
+	return func(t *Transport[T]) { t.base = base }
+}
+
+// WithRetryOnUnauthorized is the Transport Option to force a refresh and retry a
+// request (once) when its response has status 401 Unauthorized. Requests whose
+// body cannot be replayed (i.e. with a non-nil Body but a nil GetBody) are not retried.
+//
+// At most one refresh is forced per value: concurrent and subsequent requests rejected
+// with the same value share the refresh forced by the first of them (and its outcome),
+// and requests rejected with a value which was since replaced are retried with the
+// current value. Refreshes are further rate limited by WithUnauthorizedRefreshInterval,
+// such that a server which rejects every value cannot cause a refresh per request.
+func WithRetryOnUnauthorized[T any]() Option[T] {
+	return func(t *Transport[T]) { t.retryOnUnauthorized = true }
+}
+
+// WithUnauthorizedRefreshInterval is the Transport Option to override the minimum interval
+// between refreshes forced by responses with status 401 Unauthorized (see WithRetryOnUnauthorized)
+// which is DefaultUnauthorizedRefreshInterval by default.
+func WithUnauthorizedRefreshInterval[T any](interval time.Duration) Option[T] {
+	return func(t *Transport[T]) { t.unauthorizedRefreshInterval = interval }
+}
+
+// Transport is an http.RoundTripper which decorates outgoing
+// requests with the fresh value of a refresh.Refresher.
+type Transport[T any] struct {
+	refresher refresh.Refresher[T]
+	decorate  DecorateFunc[T]
+
+	base                        http.RoundTripper
+	retryOnUnauthorized         bool
+	unauthorizedRefreshInterval time.Duration
+
+	mu     sync.Mutex
+	forced *forcedRefresh[T] // the refresh forced for the last rejected value
+}
+
+// forcedRefresh is a refresh forced because a value was rejected.
+type forcedRefresh[T any] struct {
+	rejected *refresh.Refreshable[T]
+	at       time.Time
+	done     chan struct{}
+	value    *refresh.Refreshable[T]
+	err      error
+}
+
+// ensure Transport implements http.RoundTripper
+var _ http.RoundTripper = (*Transport[any])(nil)
+
+// NewTransport returns a Transport which decorates outgoing requests with
+// the fresh value of the given refresh.Refresher using the given DecorateFunc.
+func NewTransport[T any](r refresh.Refresher[T], decorate DecorateFunc[T], opts ...Option[T]) *Transport[T] {
+	t := &Transport[T]{
+		refresher:                   r,
+		decorate:                    decorate,
+		base:                        http.DefaultTransport,
+		unauthorizedRefreshInterval: DefaultUnauthorizedRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// RoundTrip carries out a request decorated with the fresh value.
+func (t *Transport[T]) RoundTrip(req *http.Request) (*http.Response, error) {
+	current, err := t.refresher.GetFresh(req.Context())
+	if err != nil {
+		closeBody(req)
+		return nil, fmt.Errorf("failed to get fresh value: %v", err)
+	}
+
+	resp, err := t.base.RoundTrip(t.decorated(req, current.Value))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !t.retryOnUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	refreshed, err := t.refreshRejected(req.Context(), current)
+	if err != nil {
+		return resp, nil // the original response is more useful than the refresh error
+	}
+
+	retry := t.decorated(req, refreshed.Value)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+// refreshRejected returns a value to retry a request rejected with the given value with. A
+// refresh is forced unless one was already forced for the value, in which case its outcome is
+// returned, or the value was already replaced, in which case the current value is returned.
+func (t *Transport[T]) refreshRejected(ctx context.Context, rejected *refresh.Refreshable[T]) (*refresh.Refreshable[T], error) {
+	t.mu.Lock()
+	forced := t.forced
+	if forced != nil && forced.rejected == rejected {
+		t.mu.Unlock()
+		select {
+		case <-forced.done:
+			return forced.value, forced.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if current := t.refresher.GetCurrent(); current != nil && current != rejected {
+		t.mu.Unlock()
+		return current, nil
+	}
+	if forced != nil && time.Since(forced.at) < t.unauthorizedRefreshInterval {
+		t.mu.Unlock()
+		return nil, errRefreshRateLimited
+	}
+	forced = &forcedRefresh[T]{rejected: rejected, at: time.Now(), done: make(chan struct{})}
+	t.forced = forced
+	t.mu.Unlock()
+
+	forced.value, forced.err = t.refresher.ForceRefresh(ctx)
+	if forced.err != nil && ctx.Err() != nil {
+		// the refresh was cut short by this request, let others force it again
+		t.mu.Lock()
+		if t.forced == forced {
+			t.forced = nil
+		}
+		t.mu.Unlock()
+	}
+	close(forced.done)
+	return forced.value, forced.err
+}
+
+// decorated returns a clone of the given request, decorated with the given value.
+func (t *Transport[T]) decorated(req *http.Request, value T) *http.Request {
+	clone := req.Clone(req.Context())
+	t.decorate(clone, value)
+	return clone
+}
+
+// closeBody closes a request's body, as RoundTrippers must do even on errors.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+func TestRetryOnUnauthorizedBoundsRefreshes(t *testing.T) {
+	var issued atomic.Int64
+	r := refresh.NewRefresher(func(context.Context) (*refresh.Refreshable[int], error) {
+		now := time.Now()
+		return &refresh.Refreshable[int]{Value: int(issued.Add(1)), IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+	})
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var accepted, requests atomic.Int64 // the accepted token, zero to reject all
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		if req.Header.Get("Authorization") != strconv.FormatInt(accepted.Load(), 10) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	const interval = 100 * time.Millisecond
+	client := &http.Client{Transport: NewTransport(r,
+		func(req *http.Request, token int) { req.Header.Set("Authorization", strconv.Itoa(token)) },
+		WithBase[int](server.Client().Transport),
+		WithRetryOnUnauthorized[int](),
+		WithUnauthorizedRefreshInterval[int](interval),
+	)}
+	get := func(expectStatus int) {
+		t.Helper()
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expectStatus {
+			t.Errorf("got status %d, expected %d", resp.StatusCode, expectStatus)
+		}
+	}
+
+	// concurrent requests rejected with the same value share a single refresh
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			get(http.StatusUnauthorized)
+		}()
+	}
+	wg.Wait()
+	// subsequent requests do not refresh again within the interval
+	for i := 0; i < 10; i++ {
+		get(http.StatusUnauthorized)
+	}
+	if n := issued.Load(); n != 2 {
+		t.Errorf("value issued %d times for 20 rejected requests, expected a single forced refresh", n)
+	}
+	if n := requests.Load(); n > 40 {
+		t.Errorf("server received %d requests for 20 requests, expected at most one retry each", n)
+	}
+
+	// once the interval elapsed, a rejected request refreshes the value and is retried
+	accepted.Store(3)
+	time.Sleep(interval)
+	get(http.StatusOK)
+	get(http.StatusOK)
+	if n := issued.Load(); n != 3 {
+		t.Errorf("value issued %d times, expected %d", n, 3)
+	}
+}
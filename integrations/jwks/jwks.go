@@ -0,0 +1,397 @@
+// Package jwks provides helpers for keeping a JSON Web Key Set (RFC 7517) fresh
+// with a refresh.Refresher, e.g. to verify JWTs issued by an identity provider.
+//
+// The key set's expiry is taken from the HTTP caching headers of the endpoint
+// serving it, and the set is refreshed ahead of time (and right away whenever a
+// token refers to an unknown key, as happens after a key rotation):
+//
+//	r := jwks.NewRefresher(http.DefaultClient, "https://idp.example.com/.well-known/jwks.json")
+//	keyfunc := jwks.Keyfunc(r)
+//
+//	// e.g. with github.com/golang-jwt/jwt/v5
+//	token, err := jwt.Parse(raw, func(t *jwt.Token) (any, error) { return keyfunc(t.Header) })
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+const (
+	// DefaultLifetime is the lifetime assumed for key sets served without caching headers.
+	DefaultLifetime = time.Hour
+
+	// MinLifetime is the minimum lifetime assumed for key sets, regardless of caching
+	// headers, such that endpoints which disable caching are not polled continuously.
+	MinLifetime = time.Minute
+
+	// DefaultUnknownKeyRefreshInterval is the default minimum interval between
+	// refreshes triggered by tokens referring to unknown keys.
+	DefaultUnknownKeyRefreshInterval = 5 * time.Minute
+
+	// maxResponseSize bounds the size of key set responses.
+	maxResponseSize = 1 << 20
+
+	// unknownKeyRefreshTimeout bounds refreshes triggered by unknown keys.
+	unknownKeyRefreshTimeout = 10 * time.Second
+)
+
+// ErrUnknownKey is matched (with errors.Is) by the errors returned
+// by Keyfunc(s) when no key matches a token.
+var ErrUnknownKey = errors.New("unknown key")
+
+// UnknownKeyError is the error returned by Keyfunc(s) when no key matches a token.
+type UnknownKeyError struct {
+	ID string
+}
+
+// Error returns the error's message.
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("unknown key %q", e.ID)
+}
+
+// Is returns true for ErrUnknownKey.
+func (e *UnknownKeyError) Is(target error) bool {
+	return target == ErrUnknownKey
+}
+
+// Key represents a public key of a JSON Web Key Set.
+type Key struct {
+	ID        string
+	Algorithm string
+	Use       string
+	Public    crypto.PublicKey
+}
+
+// Set represents a JSON Web Key Set.
+type Set struct {
+	Keys []*Key
+
+	// Skipped holds the reasons why keys of the set were skipped, i.e. keys which are
+	// invalid or of unsupported types, such that they can be reported (e.g. logged).
+	Skipped []error
+}
+
+// Key returns the key with the given ID.
+func (s *Set) Key(id string) (*Key, bool) {
+	for _, key := range s.Keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// jwk is the JSON representation of a JSON Web Key.
+type jwk struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+// Parse parses a JSON Web Key Set. Supported key types are RSA, EC (P-256, P-384, P-521),
+// and OKP (Ed25519). Keys which are invalid or of unsupported types (e.g. symmetric keys) are
+// skipped, and reported in the set's Skipped errors, such that a single unexpected key does not
+// prevent the use of the others. An error is returned if all of the set's keys were skipped.
+func Parse(data []byte) (*Set, error) {
+	var raw struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode key set: %v", err)
+	}
+	set := &Set{Keys: make([]*Key, 0, len(raw.Keys))}
+	for i, k := range raw.Keys {
+		public, err := k.publicKey()
+		if err != nil {
+			set.Skipped = append(set.Skipped, fmt.Errorf("invalid key %d (%q): %v", i, k.ID, err))
+			continue
+		}
+		if public == nil {
+			set.Skipped = append(set.Skipped, fmt.Errorf("unsupported key %d (%q): type %q, curve %q", i, k.ID, k.KeyType, k.Curve))
+			continue
+		}
+		set.Keys = append(set.Keys, &Key{ID: k.ID, Algorithm: k.Algorithm, Use: k.Use, Public: public})
+	}
+	if len(set.Keys) == 0 && len(set.Skipped) > 0 {
+		return nil, fmt.Errorf("no usable keys in key set: %v", errors.Join(set.Skipped...))
+	}
+	return set, nil
+}
+
+// publicKey returns the public key represented by the JSON Web
+// Key, or nil if the key's type or curve is not supported.
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %v", err)
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %v", err)
+		}
+		if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var validator ecdh.Curve
+		switch k.Curve {
+		case "P-256":
+			curve, validator = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, validator = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, validator = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %v", err)
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %v", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x.Bytes()) > size || len(y.Bytes()) > size {
+			return nil, errors.New("coordinates too large")
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4 // uncompressed
+		x.FillBytes(point[1 : 1+size])
+		y.FillBytes(point[1+size:])
+		if _, err := validator.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid point: %v", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %v", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid public key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, nil
+	}
+}
+
+// decodeInt decodes a base64url (unpadded) encoded big-endian unsigned integer.
+func decodeInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("missing value")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// RefreshFunc returns a refresh.RefreshFunc which fetches the JSON Web Key Set at the given URL,
+// populating the resulting Refreshable's ExpiresAt from the response's caching headers.
+func RefreshFunc(client *http.Client, url string) refresh.RefreshFunc[*Set] {
+	return func(ctx context.Context) (*refresh.Refreshable[*Set], error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %v", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch key set: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch key set: unexpected status %s", resp.Status)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key set: %v", err)
+		}
+		set, err := Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		return &refresh.Refreshable[*Set]{
+			Value:     set,
+			IssuedAt:  now,
+			ExpiresAt: now.Add(lifetimeOf(resp.Header, now)),
+		}, nil
+	}
+}
+
+// lifetimeOf returns the lifetime of a response as per its Cache-Control
+// (max-age, less any Age) or Expires headers, or DefaultLifetime if neither
+// is set, and no shorter than MinLifetime.
+func lifetimeOf(header http.Header, now time.Time) time.Duration {
+	lifetime := DefaultLifetime
+	if maxAge, ok := maxAgeOf(header.Get("Cache-Control")); ok {
+		lifetime = maxAge
+		if age, err := strconv.Atoi(strings.TrimSpace(header.Get("Age"))); err == nil && age > 0 {
+			lifetime -= time.Duration(age) * time.Second
+		}
+	} else if expires, err := http.ParseTime(header.Get("Expires")); err == nil {
+		lifetime = expires.Sub(now)
+	}
+	if lifetime < MinLifetime {
+		return MinLifetime
+	}
+	return lifetime
+}
+
+// maxAgeOf returns the max-age directive of a Cache-Control header, if any.
+// The no-store and no-cache directives are interpreted as a max-age of zero.
+func maxAgeOf(cacheControl string) (time.Duration, bool) {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0, true
+		case "max-age":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// NewRefresher returns a refresh.Refresher which keeps the
+// JSON Web Key Set at the given URL fresh.
+func NewRefresher(client *http.Client, url string, opts ...refresh.Option[*Set]) refresh.Refresher[*Set] {
+	return refresh.NewRefresher(RefreshFunc(client, url), opts...)
+}
+
+// KeyfuncOption represents a Keyfunc configuration option.
+type KeyfuncOption func(*keyfunc)
+
+// WithUnknownKeyRefreshInterval is the KeyfuncOption to override the minimum interval between
+// refreshes triggered by tokens referring to unknown keys (DefaultUnknownKeyRefreshInterval).
+// This bounds the load that tokens with made up key IDs can put on the key set's endpoint.
+// A negative interval disables such refreshes.
+func WithUnknownKeyRefreshInterval(interval time.Duration) KeyfuncOption {
+	return func(k *keyfunc) { k.unknownKeyRefreshInterval = interval }
+}
+
+// keyfunc looks up verification keys for tokens in a refreshed JSON Web Key Set.
+type keyfunc struct {
+	refresher                 refresh.Refresher[*Set]
+	unknownKeyRefreshInterval time.Duration
+
+	mu                   sync.Mutex
+	lastUnknownKeyForced time.Time
+}
+
+// Keyfunc returns a function which returns the public key with which to verify a token, given
+// the token's (decoded) JOSE header, which is the shape of key functions expected by popular JWT
+// libraries (e.g. github.com/golang-jwt/jwt, whose Token has such a Header).
+//
+// The key is looked up by the header's "kid" (or is the set's only key if the header has none),
+// and must match the header's "alg" if the key specifies an algorithm. Tokens referring to unknown
+// keys trigger an immediate refresh of the key set, as rate limited by WithUnknownKeyRefreshInterval,
+// such that keys which were rotated in are picked up right away.
+func Keyfunc(r refresh.Refresher[*Set], opts ...KeyfuncOption) func(header map[string]any) (any, error) {
+	k := &keyfunc{refresher: r, unknownKeyRefreshInterval: DefaultUnknownKeyRefreshInterval}
+	for _, opt := range opts {
+		opt(k)
+	}
+	return k.key
+}
+
+// key returns the public key with which to verify a token with the given header.
+func (k *keyfunc) key(header map[string]any) (any, error) {
+	kid, _ := header["kid"].(string)
+	alg, _ := header["alg"].(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), unknownKeyRefreshTimeout)
+	defer cancel()
+
+	current, err := k.refresher.GetFresh(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key set: %v", err)
+	}
+	key, ok := lookup(current.Value, kid)
+	if !ok && k.allowUnknownKeyRefresh() {
+		if current, err = k.refresher.ForceRefresh(ctx); err != nil {
+			return nil, fmt.Errorf("failed to refresh key set: %v", err)
+		}
+		key, ok = lookup(current.Value, kid)
+	}
+	if !ok {
+		return nil, &UnknownKeyError{ID: kid}
+	}
+	if key.Algorithm != "" && alg != key.Algorithm {
+		return nil, fmt.Errorf("key %q is for algorithm %q, not %q", kid, key.Algorithm, alg)
+	}
+	return key.Public, nil
+}
+
+// lookup returns the key with the given ID, or the set's only key if no ID is given.
+func lookup(set *Set, kid string) (*Key, bool) {
+	if set == nil {
+		return nil, false
+	}
+	if kid == "" {
+		if len(set.Keys) == 1 {
+			return set.Keys[0], true
+		}
+		return nil, false
+	}
+	return set.Key(kid)
+}
+
+// allowUnknownKeyRefresh returns true if a refresh triggered by an unknown key is
+// allowed now, in which case the time of the last such refresh is updated.
+func (k *keyfunc) allowUnknownKeyRefresh() bool {
+	if k.unknownKeyRefreshInterval < 0 {
+		return false
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	if !k.lastUnknownKeyForced.IsZero() && now.Sub(k.lastUnknownKeyForced) < k.unknownKeyRefreshInterval {
+		return false
+	}
+	k.lastUnknownKeyForced = now
+	return true
+}
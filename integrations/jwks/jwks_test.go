@@ -0,0 +1,99 @@
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func encodeInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func TestKeyfuncSkipsInvalidKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa","alg":"RS256","n":%q,"e":%q},
+		{"kty":"EC","kid":"ec","alg":"ES256","crv":"P-256","x":%q,"y":%q},
+		{"kty":"oct","kid":"symmetric","k":"c2VjcmV0"},
+		{"kty":"EC","kid":"bad","crv":"P-256","x":%q,"y":"not*base64"}
+	]}`,
+		encodeInt(rsaKey.N), encodeInt(big.NewInt(int64(rsaKey.E))),
+		encodeInt(ecKey.X), encodeInt(ecKey.Y), encodeInt(ecKey.X),
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=600")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	r := NewRefresher(server.Client(), server.URL)
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	set := r.GetCurrent().Value
+	if len(set.Keys) != 2 {
+		t.Errorf("parsed %d keys, expected the RSA and EC keys", len(set.Keys))
+	}
+	if len(set.Skipped) != 2 ||
+		!strings.Contains(set.Skipped[0].Error(), `unsupported key 2 ("symmetric")`) ||
+		!strings.Contains(set.Skipped[1].Error(), `invalid key 3 ("bad")`) {
+		t.Errorf("skipped %v, expected the symmetric and bad keys", set.Skipped)
+	}
+
+	keyfunc := Keyfunc(r, WithUnknownKeyRefreshInterval(-1))
+	key, err := keyfunc(map[string]any{"kid": "rsa", "alg": "RS256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rsaKey.PublicKey.Equal(key) {
+		t.Error("wrong RSA key")
+	}
+	key, err = keyfunc(map[string]any{"kid": "ec", "alg": "ES256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecKey.PublicKey.Equal(key) {
+		t.Error("wrong EC key")
+	}
+	if _, err := keyfunc(map[string]any{"kid": "ec", "alg": "RS256"}); err == nil {
+		t.Error("key returned for the wrong algorithm")
+	}
+	for _, kid := range []string{"symmetric", "bad", "missing"} {
+		if _, err := keyfunc(map[string]any{"kid": kid, "alg": "RS256"}); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("got %v for key %q, expected %v", err, kid, ErrUnknownKey)
+		}
+	}
+}
+
+func TestParseFailsWithoutUsableKeys(t *testing.T) {
+	if _, err := Parse([]byte(`{"keys":[{"kty":"oct","k":"c2VjcmV0"},{"kty":"RSA","n":"!","e":"AQAB"}]}`)); err == nil {
+		t.Error("key set without usable keys was accepted")
+	}
+	set, err := Parse([]byte(`{"keys":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Keys) != 0 || len(set.Skipped) != 0 {
+		t.Errorf("parsed %+v from an empty key set", set)
+	}
+}
@@ -0,0 +1,170 @@
+// Package kubernetes provides helpers for keeping values stored in Kubernetes
+// Secrets or ConfigMaps fresh with a refresh.Refresher, and a refresh.Storage
+// which persists refreshed values in a Secret such that they are shared across
+// the pods of a workload (e.g. to avoid every replica minting its own token).
+//
+// Objects are read through the Client interface and watched through Watcher, if the
+// Client implements it, e.g. by adapting a client-go SecretInterface or ConfigMapInterface.
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/storage"
+)
+
+var (
+	// ErrNotFound is the error returned by Client(s) when the requested object does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is the error returned by Client(s) when an object cannot be written
+	// because its resource version does not match the version stored in the cluster.
+	ErrConflict = errors.New("conflict")
+)
+
+// Object represents the data of a Secret or ConfigMap.
+type Object struct {
+	// Data is the object's data by key.
+	Data map[string][]byte
+
+	// ResourceVersion is the object's resource version,
+	// which is empty for objects not yet created.
+	ResourceVersion string
+}
+
+// Client represents read access to the Secrets or ConfigMaps of a namespace.
+type Client interface {
+	// Get returns the named object, or ErrNotFound if it does not exist.
+	Get(ctx context.Context, name string) (*Object, error)
+}
+
+// Watcher is implemented by Client(s) which can stream changes to objects.
+type Watcher interface {
+	// Watch returns a channel on which the named object is sent whenever it
+	// changes. The channel is closed when the watch ends (e.g. when the given
+	// context is done or the API server closes the connection).
+	Watch(ctx context.Context, name string) (<-chan *Object, error)
+}
+
+// Writer represents read-write access to the Secrets of a namespace.
+type Writer interface {
+	Client
+
+	// Put creates the named object if its ResourceVersion is empty, or otherwise updates
+	// it, returning ErrConflict if the ResourceVersion is not the current one.
+	Put(ctx context.Context, name string, object *Object) error
+}
+
+// NotFoundError is the error returned when an object does not exist,
+// or a key is not present in it.
+type NotFoundError struct {
+	Name string
+	Key  string
+}
+
+// Error returns the error's message.
+func (e *NotFoundError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%q not found", e.Name)
+	}
+	return fmt.Sprintf("key %q not found in %q", e.Key, e.Name)
+}
+
+// Is returns true for ErrNotFound and storage.ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound || target == storage.ErrNotFound
+}
+
+// ParseFunc builds a Refreshable from the data stored under an object's key.
+type ParseFunc[T any] func(data []byte) (*refresh.Refreshable[T], error)
+
+// RefreshFunc returns a refresh.RefreshFunc which reads the data under the given key
+// of the named object, and builds a Refreshable from it with the given ParseFunc.
+//
+// Use Watch to refresh as soon as the object changes, rather than on a schedule.
+func RefreshFunc[T any](client Client, name, key string, parse ParseFunc[T]) refresh.RefreshFunc[T] {
+	return func(ctx context.Context) (*refresh.Refreshable[T], error) {
+		data, err := getKey(ctx, client, name, key)
+		if err != nil {
+			return nil, err
+		}
+		refreshable, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %q of %q: %v", key, name, err)
+		}
+		return refreshable, nil
+	}
+}
+
+// getKey returns the data under the given key of the named object.
+func getKey(ctx context.Context, client Client, name, key string) ([]byte, error) {
+	object, err := client.Get(ctx, name)
+	if errors.Is(err, ErrNotFound) {
+		return nil, &NotFoundError{Name: name}
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, ok := object.Data[key]
+	if !ok {
+		return nil, &NotFoundError{Name: name, Key: key}
+	}
+	return data, nil
+}
+
+// rewatchDelay is the delay before re-establishing a watch which ended or failed.
+const rewatchDelay = 5 * time.Second
+
+// Watch forces an immediate refresh of the given Refresher whenever the named
+// object changes, if the given Client is a Watcher, such that changes propagate
+// without waiting for the next scheduled refresh. Watches which end (as they
+// routinely do) are re-established.
+//
+// The returned function stops watching, which also happens once the Refresher stops.
+// If the Client is not a Watcher, Watch does nothing and the Refresher keeps refreshing
+// on its schedule only.
+func Watch[T any](r refresh.Refresher[T], client Client, name string) (stop func()) {
+	watcher, ok := client.(Watcher)
+	if !ok {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	stop = func() { once.Do(cancel) }
+
+	go func() {
+		defer stop()
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-r.Done():
+				stop()
+			}
+		}()
+
+		lastVersion := ""
+		for ctx.Err() == nil {
+			changes, err := watcher.Watch(ctx, name)
+			if err == nil {
+				for object := range changes {
+					if object == nil || (object.ResourceVersion != "" && object.ResourceVersion == lastVersion) {
+						continue
+					}
+					lastVersion = object.ResourceVersion
+					_, _ = r.ForceRefresh(ctx)
+				}
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(rewatchDelay):
+			}
+		}
+	}()
+	return stop
+}
@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/storage"
+)
+
+// maxPutAttempts is the number of times a write is attempted
+// when it conflicts with concurrent writes to the same object.
+const maxPutAttempts = 3
+
+// NewStorage returns a refresh.Storage which persists Refreshables serialized with the
+// given Codec under the given key of the named Secret, creating the Secret if necessary.
+// Other keys of the Secret are preserved, so a single Secret can hold several values.
+func NewStorage[T any](
+	client Writer,
+	name string,
+	key string,
+	codec storage.Codec[T],
+	opts ...storage.Option[T],
+) refresh.Storage[T] {
+	return storage.New(NewBackend(client, name, key), codec, opts...)
+}
+
+// NewBackend returns a storage.Backend which stores
+// data under the given key of the named Secret.
+func NewBackend(client Writer, name, key string) storage.Backend {
+	return storage.BackendFromFunctions(
+		func(ctx context.Context) ([]byte, error) {
+			return getKey(ctx, client, name, key)
+		},
+		func(ctx context.Context, data []byte) error {
+			return putKey(ctx, client, name, key, data)
+		},
+	)
+}
+
+// putKey stores data under the given key of the named object, retrying
+// writes which conflict with concurrent writes (e.g. from other pods).
+func putKey(ctx context.Context, client Writer, name, key string, data []byte) error {
+	var err error
+	for attempt := 0; attempt < maxPutAttempts; attempt++ {
+		var object *Object
+		if object, err = client.Get(ctx, name); err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("failed to get %q: %v", name, err)
+			}
+			object = &Object{}
+		}
+		updated := &Object{Data: maps.Clone(object.Data), ResourceVersion: object.ResourceVersion}
+		if updated.Data == nil {
+			updated.Data = make(map[string][]byte)
+		}
+		updated.Data[key] = data
+
+		if err = client.Put(ctx, name, updated); !errors.Is(err, ErrConflict) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put %q: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+module github.com/adrianosela/refresh/integrations/oauth2
+
+go 1.22
+
+require (
+	github.com/adrianosela/refresh v0.0.0-00010101000000-000000000000
+	golang.org/x/oauth2 v0.26.0
+)
+
+replace github.com/adrianosela/refresh => ../..
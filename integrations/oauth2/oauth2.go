@@ -0,0 +1,116 @@
+// Package oauth2 provides helpers for keeping OAuth 2.0 tokens fresh
+// with a refresh.Refresher, and for using them with golang.org/x/oauth2.
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/adrianosela/refresh"
+)
+
+// DefaultLifetime is the lifetime assumed for tokens which carry no expiry.
+const DefaultLifetime = time.Hour
+
+// tokenSource is an oauth2.TokenSource backed by a refresh.Refresher.
+type tokenSource struct {
+	refresher refresh.Refresher[*oauth2.Token]
+}
+
+// TokenSource returns an oauth2.TokenSource which returns the current token
+// of the given refresh.Refresher. If the current token is expired, an immediate
+// refresh is triggered and waited on.
+//
+// The returned oauth2.TokenSource is safe for concurrent use and can be
+// used with oauth2.NewClient to build an authenticating http.Client.
+func TokenSource(r refresh.Refresher[*oauth2.Token]) oauth2.TokenSource {
+	return &tokenSource{refresher: r}
+}
+
+// Token returns the current token.
+func (ts *tokenSource) Token() (*oauth2.Token, error) {
+	current, err := ts.refresher.GetFresh(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fresh token: %v", err)
+	}
+	if current == nil || current.Value == nil {
+		return nil, errors.New("refresher returned no token")
+	}
+	return current.Value, nil
+}
+
+// RefreshFunc returns a refresh.RefreshFunc which acquires a new token with the given
+// function, populating the resulting Refreshable's IssuedAt and ExpiresAt from the token.
+func RefreshFunc(fetch func(context.Context) (*oauth2.Token, error)) refresh.RefreshFunc[*oauth2.Token] {
+	return func(ctx context.Context) (*refresh.Refreshable[*oauth2.Token], error) {
+		token, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, errors.New("token source returned no token")
+		}
+		return refreshableFromToken(token, time.Now()), nil
+	}
+}
+
+// NewClientCredentialsRefresher returns a refresh.Refresher which keeps a token
+// acquired with the OAuth 2.0 client credentials flow fresh.
+func NewClientCredentialsRefresher(
+	config *clientcredentials.Config,
+	opts ...refresh.Option[*oauth2.Token],
+) refresh.Refresher[*oauth2.Token] {
+	return refresh.NewRefresher(RefreshFunc(config.Token), opts...)
+}
+
+// NewConfigRefresher returns a refresh.Refresher which keeps a token fresh
+// using the refresh token grant of the given oauth2.Config, starting with
+// the refresh token in the given (initial) token, or in the token loaded
+// from storage (if any).
+//
+// Refresh tokens rotated by the authorization server are carried forward.
+func NewConfigRefresher(
+	config *oauth2.Config,
+	token *oauth2.Token,
+	opts ...refresh.Option[*oauth2.Token],
+) refresh.Refresher[*oauth2.Token] {
+	return refresh.NewRefresherWithPrevious(func(
+		ctx context.Context,
+		previous *refresh.Refreshable[*oauth2.Token],
+	) (*refresh.Refreshable[*oauth2.Token], error) {
+		refreshToken := token.RefreshToken
+		if previous != nil && previous.Value != nil && previous.Value.RefreshToken != "" {
+			refreshToken = previous.Value.RefreshToken
+		}
+		// a token without an access token is never valid, forcing the token source to refresh it
+		newToken, err := config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+		if err != nil {
+			return nil, err
+		}
+		if newToken == nil {
+			return nil, errors.New("token source returned no token")
+		}
+		if newToken.RefreshToken == "" {
+			newToken.RefreshToken = refreshToken
+		}
+		return refreshableFromToken(newToken, time.Now()), nil
+	}, opts...)
+}
+
+// refreshableFromToken wraps a token in a Refreshable.
+func refreshableFromToken(token *oauth2.Token, now time.Time) *refresh.Refreshable[*oauth2.Token] {
+	expiresAt := token.Expiry
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(DefaultLifetime)
+	}
+	return &refresh.Refreshable[*oauth2.Token]{
+		Value:     token,
+		IssuedAt:  now,
+		ExpiresAt: expiresAt,
+	}
+}
@@ -0,0 +1,91 @@
+// Package sqlcreds provides a database/sql driver.Connector which opens connections with the
+// current credentials of a refresh.Refresher, such that short-lived database credentials (e.g.
+// Vault dynamic secrets or cloud IAM authentication tokens) rotate without recreating the
+// *sql.DB connection pool:
+//
+//	db := sql.OpenDB(sqlcreds.NewConnector(&pq.Driver{}, r, func(creds *api.Secret) (string, error) {
+//		return fmt.Sprintf("postgres://%s:%s@db:5432/app", creds.Data["username"], creds.Data["password"]), nil
+//	}))
+//	db.SetConnMaxLifetime(10 * time.Minute) // shorter than the credentials' lifetime
+//
+// Credentials are only used when opening new connections, so set the pool's maximum connection
+// lifetime to recycle connections before the credentials they were opened with are revoked.
+package sqlcreds
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+
+	"github.com/adrianosela/refresh"
+)
+
+// DSNFunc builds a driver-specific data source name from the current credentials.
+type DSNFunc[T any] func(credentials T) (string, error)
+
+// connector is a driver.Connector which opens connections with
+// a data source name built from a refresher's current value.
+type connector[T any] struct {
+	driver    driver.Driver
+	refresher refresh.Refresher[T]
+	dsn       DSNFunc[T]
+
+	// managed by connectorFor(), caches the driver's connector for the last data source name
+	mu            sync.Mutex
+	lastDSN       string
+	lastConnector driver.Connector
+}
+
+// ensure connector implements driver.Connector
+var _ driver.Connector = (*connector[any])(nil)
+
+// NewConnector returns a driver.Connector which opens connections with the given driver and a
+// data source name built with the given DSNFunc from the refresher's current (fresh) value. It
+// is meant to be used with sql.OpenDB.
+func NewConnector[T any](d driver.Driver, r refresh.Refresher[T], dsn DSNFunc[T]) driver.Connector {
+	return &connector[T]{driver: d, refresher: r, dsn: dsn}
+}
+
+// Connect opens a connection with the current credentials.
+func (c *connector[T]) Connect(ctx context.Context) (driver.Conn, error) {
+	current, err := c.refresher.GetFresh(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fresh credentials: %v", err)
+	}
+	dsn, err := c.dsn(current.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build data source name: %v", err)
+	}
+
+	if _, ok := c.driver.(driver.DriverContext); !ok {
+		return c.driver.Open(dsn)
+	}
+	dc, err := c.connectorFor(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return dc.Connect(ctx)
+}
+
+// Driver returns the underlying driver.
+func (c *connector[T]) Driver() driver.Driver {
+	return c.driver
+}
+
+// connectorFor returns the driver's connector for the given data source name, which
+// is only parsed (by the driver) again when the credentials change.
+func (c *connector[T]) connectorFor(dsn string) (driver.Connector, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lastConnector != nil && c.lastDSN == dsn {
+		return c.lastConnector, nil
+	}
+	dc, err := c.driver.(driver.DriverContext).OpenConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connector: %v", err)
+	}
+	c.lastDSN, c.lastConnector = dsn, dc
+	return dc, nil
+}
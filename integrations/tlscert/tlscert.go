@@ -0,0 +1,150 @@
+// Package tlscert provides helpers for keeping a tls.Certificate fresh with
+// a refresh.Refresher, and for serving it through a tls.Config.
+//
+// Certificates may be loaded from files, from PEM data fetched by any means,
+// or acquired with a user callback (e.g. one wrapping an ACME client such as
+// golang.org/x/crypto/acme).
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// FromFunc returns a refresh.RefreshFunc which acquires a new certificate with the given
+// function, populating the resulting Refreshable's IssuedAt and ExpiresAt from the leaf
+// certificate's NotBefore and NotAfter.
+func FromFunc(fetch func(context.Context) (*tls.Certificate, error)) refresh.RefreshFunc[*tls.Certificate] {
+	return func(ctx context.Context) (*refresh.Refreshable[*tls.Certificate], error) {
+		cert, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if cert == nil {
+			return nil, errors.New("no certificate acquired")
+		}
+		leaf, err := leafOf(cert)
+		if err != nil {
+			return nil, err
+		}
+		cert.Leaf = leaf
+		return &refresh.Refreshable[*tls.Certificate]{
+			Value:     cert,
+			IssuedAt:  leaf.NotBefore,
+			ExpiresAt: leaf.NotAfter,
+		}, nil
+	}
+}
+
+// FromPEM returns a refresh.RefreshFunc which acquires a new certificate
+// from PEM encoded certificate (chain) and private key data returned by
+// the given function.
+func FromPEM(fetch func(context.Context) (certPEM []byte, keyPEM []byte, err error)) refresh.RefreshFunc[*tls.Certificate] {
+	return FromFunc(func(ctx context.Context) (*tls.Certificate, error) {
+		certPEM, keyPEM, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key pair: %v", err)
+		}
+		return &cert, nil
+	})
+}
+
+// FromFiles returns a refresh.RefreshFunc which (re)loads a certificate from PEM encoded
+// certificate (chain) and private key files, e.g. those rotated by an external agent.
+func FromFiles(certFile, keyFile string) refresh.RefreshFunc[*tls.Certificate] {
+	return FromPEM(func(context.Context) ([]byte, []byte, error) {
+		certPEM, err := os.ReadFile(certFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read certificate file: %v", err)
+		}
+		keyPEM, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read key file: %v", err)
+		}
+		return certPEM, keyPEM, nil
+	})
+}
+
+// GetCertificate returns a function suitable for tls.Config.GetCertificate
+// which returns the current certificate of the given refresh.Refresher.
+func GetCertificate(r refresh.Refresher[*tls.Certificate]) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return current(hello.Context(), r)
+	}
+}
+
+// GetClientCertificate returns a function suitable for tls.Config.GetClientCertificate
+// which returns the current certificate of the given refresh.Refresher.
+func GetClientCertificate(r refresh.Refresher[*tls.Certificate]) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(req *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return current(req.Context(), r)
+	}
+}
+
+// current returns the current (unexpired) certificate of a refresh.Refresher.
+func current(ctx context.Context, r refresh.Refresher[*tls.Certificate]) (*tls.Certificate, error) {
+	refreshable, err := r.GetFresh(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fresh certificate: %v", err)
+	}
+	if refreshable == nil || refreshable.Value == nil {
+		return nil, errors.New("refresher returned no certificate")
+	}
+	return refreshable.Value, nil
+}
+
+// strategyBeforeNotAfter is a refresh.RefreshStrategy keyed off a certificate's NotAfter.
+type strategyBeforeNotAfter struct {
+	lead time.Duration
+}
+
+// NewBeforeNotAfterStrategy returns a refresh.RefreshStrategy which refreshes certificates
+// the given duration before their leaf certificate's NotAfter (or immediately if that time
+// already passed). For certificates whose leaf cannot be parsed, the Refreshable's ExpiresAt
+// is used instead.
+func NewBeforeNotAfterStrategy(lead time.Duration) refresh.RefreshStrategy[*tls.Certificate] {
+	return &strategyBeforeNotAfter{lead: lead}
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyBeforeNotAfter) GetRefreshAt(refreshable *refresh.Refreshable[*tls.Certificate]) time.Time {
+	notAfter := refreshable.ExpiresAt
+	if refreshable.Value != nil {
+		if leaf, err := leafOf(refreshable.Value); err == nil {
+			notAfter = leaf.NotAfter
+		}
+	}
+
+	now := time.Now()
+	refreshAt := notAfter.Add(-s.lead)
+	if now.Before(refreshAt) {
+		return refreshAt
+	}
+	return now
+}
+
+// leafOf returns the (parsed) leaf of a certificate.
+func leafOf(cert *tls.Certificate) (*x509.Certificate, error) {
+	if cert.Leaf != nil {
+		return cert.Leaf, nil
+	}
+	if len(cert.Certificate) == 0 {
+		return nil, errors.New("certificate has no leaf")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse leaf certificate: %v", err)
+	}
+	return leaf, nil
+}
@@ -0,0 +1,172 @@
+// Package vault provides helpers for keeping HashiCorp Vault dynamic secrets
+// and auth tokens fresh with a refresh.Refresher.
+//
+// Leases are renewed (see refresh.WithRenewal) for as long as Vault allows, falling
+// back to re-reading the secret (or logging in again) once renewal is no longer
+// possible, e.g. when the lease is not renewable, is approaching its maximum TTL,
+// or was revoked while a value loaded from storage was not in use.
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/adrianosela/refresh"
+)
+
+// DefaultLifetime is the lifetime assumed for secrets which carry no lease duration.
+const DefaultLifetime = time.Hour
+
+// NewSecretRefresher returns a refresh.Refresher which keeps the secret at the given path
+// (e.g. "database/creds/my-role") fresh, renewing its lease (see RenewSecretFunc) when
+// possible and re-reading it (see SecretRefreshFunc) otherwise.
+func NewSecretRefresher(
+	client *api.Client,
+	path string,
+	opts ...refresh.Option[*api.Secret],
+) refresh.Refresher[*api.Secret] {
+	opts = append([]refresh.Option[*api.Secret]{
+		refresh.WithRenewal(RenewSecretFunc(client), -1, nil),
+	}, opts...)
+	return refresh.NewRefresher(SecretRefreshFunc(client, path), opts...)
+}
+
+// SecretRefreshFunc returns a refresh.RefreshFunc which reads the secret at the given path.
+func SecretRefreshFunc(client *api.Client, path string) refresh.RefreshFunc[*api.Secret] {
+	return func(ctx context.Context) (*refresh.Refreshable[*api.Secret], error) {
+		secret, err := client.Logical().ReadWithContext(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret: %v", err)
+		}
+		if secret == nil {
+			return nil, fmt.Errorf("no secret found at %q", path)
+		}
+		return refreshableFromSecret(secret, secret.LeaseDuration), nil
+	}
+}
+
+// RenewSecretFunc returns a refresh.RenewFunc which renews the lease of the current secret.
+//
+// A renewal is only accepted if it extends the lease by at least half of its current
+// duration, such that secrets approaching their maximum TTL are re-read rather than
+// renewed for ever shorter periods. Since the lease is read from the current value,
+// the leases of secrets loaded from storage are renewed as well.
+func RenewSecretFunc(client *api.Client) refresh.RenewFunc[*api.Secret] {
+	return func(ctx context.Context, current *refresh.Refreshable[*api.Secret]) (*refresh.Refreshable[*api.Secret], error) {
+		secret := current.Value
+		if secret == nil || !secret.Renewable || secret.LeaseID == "" || secret.LeaseDuration <= 0 {
+			return nil, errors.New("secret is not renewable")
+		}
+		resp, err := client.Sys().RenewWithContext(ctx, secret.LeaseID, secret.LeaseDuration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to renew lease: %v", err)
+		}
+		if resp == nil || resp.LeaseDuration < secret.LeaseDuration/2 {
+			return nil, errors.New("lease is approaching its maximum TTL")
+		}
+		// lease renewals carry no secret data, only the updated lease
+		renewed := *secret
+		renewed.LeaseDuration = resp.LeaseDuration
+		renewed.Renewable = resp.Renewable
+		return refreshableFromSecret(&renewed, renewed.LeaseDuration), nil
+	}
+}
+
+// NewTokenRefresher returns a refresh.Refresher which keeps a Vault token acquired by logging
+// in with the given auth method fresh, renewing it (see RenewTokenFunc) when possible and
+// logging in again (see TokenRefreshFunc) otherwise. The client's token is set to every new
+// token, including tokens loaded from storage.
+func NewTokenRefresher(
+	client *api.Client,
+	authMethod api.AuthMethod,
+	opts ...refresh.Option[*api.Secret],
+) refresh.Refresher[*api.Secret] {
+	opts = append([]refresh.Option[*api.Secret]{
+		refresh.WithRenewal(RenewTokenFunc(client), -1, nil),
+	}, opts...)
+	r := refresh.NewRefresher(TokenRefreshFunc(client, authMethod), opts...)
+
+	setter := &tokenSetter{client: client}
+	r.Subscribe(setter.set)
+	if current := r.GetCurrentUnsafe(); current != nil {
+		setter.set(current) // e.g. loaded from storage before subscribing
+	}
+	return r
+}
+
+// TokenRefreshFunc returns a refresh.RefreshFunc which logs in with the given auth method.
+func TokenRefreshFunc(client *api.Client, authMethod api.AuthMethod) refresh.RefreshFunc[*api.Secret] {
+	return func(ctx context.Context) (*refresh.Refreshable[*api.Secret], error) {
+		secret, err := client.Auth().Login(ctx, authMethod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to log in: %v", err)
+		}
+		if secret == nil || secret.Auth == nil {
+			return nil, errors.New("login returned no auth information")
+		}
+		return refreshableFromSecret(secret, secret.Auth.LeaseDuration), nil
+	}
+}
+
+// RenewTokenFunc returns a refresh.RenewFunc which renews the current token.
+//
+// As for secrets, a renewal is only accepted if it extends the token's TTL
+// by at least half of its current TTL.
+func RenewTokenFunc(client *api.Client) refresh.RenewFunc[*api.Secret] {
+	return func(ctx context.Context, current *refresh.Refreshable[*api.Secret]) (*refresh.Refreshable[*api.Secret], error) {
+		if current.Value == nil || current.Value.Auth == nil || !current.Value.Auth.Renewable {
+			return nil, errors.New("token is not renewable")
+		}
+		ttl := current.Value.Auth.LeaseDuration
+		resp, err := client.Auth().Token().RenewTokenAsSelfWithContext(ctx, current.Value.Auth.ClientToken, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to renew token: %v", err)
+		}
+		if resp == nil || resp.Auth == nil || resp.Auth.LeaseDuration < ttl/2 {
+			return nil, errors.New("token is approaching its maximum TTL")
+		}
+		return refreshableFromSecret(resp, resp.Auth.LeaseDuration), nil
+	}
+}
+
+// tokenSetter sets the token of a client to the newest of the tokens it is given.
+type tokenSetter struct {
+	client *api.Client
+
+	mu       sync.Mutex
+	issuedAt time.Time
+}
+
+// set sets the client's token to the given one, unless it was issued before the current one.
+func (s *tokenSetter) set(token *refresh.Refreshable[*api.Secret]) {
+	if token.Value == nil || token.Value.Auth == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token.IssuedAt.Before(s.issuedAt) {
+		return
+	}
+	s.issuedAt = token.IssuedAt
+	s.client.SetToken(token.Value.Auth.ClientToken)
+}
+
+// refreshableFromSecret wraps a secret with the given lease duration (in seconds) in a Refreshable.
+func refreshableFromSecret(secret *api.Secret, leaseDurationSeconds int) *refresh.Refreshable[*api.Secret] {
+	now := time.Now()
+	lifetime := time.Duration(leaseDurationSeconds) * time.Second
+	if lifetime <= 0 {
+		lifetime = DefaultLifetime
+	}
+	return &refresh.Refreshable[*api.Secret]{
+		Value:     secret,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(lifetime),
+	}
+}
@@ -0,0 +1,130 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/adrianosela/refresh"
+)
+
+// fakeVault is a Vault server which issues renewable database credentials.
+type fakeVault struct {
+	reads    atomic.Int64
+	renewals atomic.Int64
+	revoked  atomic.Bool
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v1/database/creds/app":
+		n := v.reads.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"lease_id":       "database/creds/app/" + strconv.FormatInt(n, 10),
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]any{"username": "user", "password": "password"},
+		})
+	case "/v1/sys/leases/renew":
+		if v.revoked.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["lease not found"]}`))
+			return
+		}
+		var req struct {
+			LeaseID string `json:"lease_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		v.renewals.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"lease_id":       req.LeaseID,
+			"lease_duration": 3600,
+			"renewable":      true,
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newClient(t *testing.T, v *fakeVault) *api.Client {
+	t.Helper()
+	server := httptest.NewServer(v)
+	t.Cleanup(server.Close)
+	config := api.DefaultConfig()
+	config.Address = server.URL
+	config.MaxRetries = 0
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken("root")
+	return client
+}
+
+func TestSecretRefresherRenewsLease(t *testing.T) {
+	v := &fakeVault{}
+	r := NewSecretRefresher(newClient(t, v), "database/creds/app")
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	renewed, err := r.ForceRefresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.reads.Load() != 1 || v.renewals.Load() != 1 {
+		t.Errorf("got %d reads and %d renewals, expected 1 of each", v.reads.Load(), v.renewals.Load())
+	}
+	if renewed.Value.Data["username"] != "user" {
+		t.Errorf("renewed secret lost its data: %v", renewed.Value.Data)
+	}
+}
+
+func TestSecretRefresherRenewsLeaseFromStorage(t *testing.T) {
+	v := &fakeVault{}
+	stored := &refresh.Refreshable[*api.Secret]{
+		Value: &api.Secret{
+			LeaseID:       "database/creds/app/stored",
+			LeaseDuration: 3600,
+			Renewable:     true,
+			Data:          map[string]any{"username": "stored"},
+		},
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	storage := refresh.StorageFromFunctions(
+		func(context.Context) (*refresh.Refreshable[*api.Secret], error) { return stored, nil },
+		func(context.Context, *refresh.Refreshable[*api.Secret]) error { return nil },
+	)
+	r := NewSecretRefresher(newClient(t, v), "database/creds/app", refresh.WithStorage(storage))
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	renewed, err := r.ForceRefresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.reads.Load() != 0 || renewed.Value.LeaseID != stored.Value.LeaseID {
+		t.Errorf("lease of the secret loaded from storage was not renewed (%d reads)", v.reads.Load())
+	}
+
+	// once the lease is lost, the secret is read again
+	v.revoked.Store(true)
+	reads := v.reads.Load()
+	if _, err := r.ForceRefresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v.reads.Load() != reads+1 {
+		t.Errorf("secret was not read again after its lease was lost")
+	}
+}
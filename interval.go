@@ -0,0 +1,36 @@
+package refresh
+
+import "time"
+
+// WithMinRefreshInterval is the refresher Option to set the minimum time between a refresh and
+// the next one, regardless of what the RefreshStrategy (or the value's RefreshAt) says. This
+// protects upstream issuers from hot refresh loops caused by pathological strategies or values
+// with tiny lifetimes, at the risk of holding on to values past their expiry.
+//
+// Explicit refreshes (e.g. via ForceRefresh) and retries of failed refreshes are not affected.
+func WithMinRefreshInterval[T any](minRefreshInterval time.Duration) Option[T] {
+	return func(r *refresher[T]) { r.minRefreshInterval = minRefreshInterval }
+}
+
+// WithMaxRefreshInterval is the refresher Option to set the maximum time between a refresh and
+// the next one, regardless of what the RefreshStrategy (or the value's RefreshAt) says, e.g. to
+// pick up revocations of long-lived values in a timely manner.
+func WithMaxRefreshInterval[T any](maxRefreshInterval time.Duration) Option[T] {
+	return func(r *refresher[T]) { r.maxRefreshInterval = maxRefreshInterval }
+}
+
+// clampRefreshAt clamps a refresh time to the refresher's minimum
+// and maximum refresh intervals (if any), counting from the given time.
+func (r *refresher[T]) clampRefreshAt(refreshAt, now time.Time) time.Time {
+	if r.maxRefreshInterval > 0 {
+		if latest := now.Add(r.maxRefreshInterval); refreshAt.After(latest) {
+			refreshAt = latest
+		}
+	}
+	if r.minRefreshInterval > 0 {
+		if earliest := now.Add(r.minRefreshInterval); refreshAt.Before(earliest) {
+			refreshAt = earliest
+		}
+	}
+	return refreshAt
+}
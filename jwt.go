@@ -0,0 +1,82 @@
+package refresh
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JWT returns a RefreshFunc which acquires a new raw JWT with the given function,
+// populating the resulting Refreshable's IssuedAt and ExpiresAt from the token's
+// "iat" and "exp" claims. The "exp" claim is required, whereas a missing "iat"
+// claim results in the time of acquisition being used instead.
+//
+// Note that the token's signature is NOT verified, the claims are only read
+// to determine the token's lifetime.
+func JWT(fetch func(context.Context) (string, error)) RefreshFunc[string] {
+	return func(ctx context.Context) (*Refreshable[string], error) {
+		token, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		issuedAt, expiresAt, err := parseJWTLifetime(token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jwt: %v", err)
+		}
+		if issuedAt.IsZero() {
+			issuedAt = time.Now()
+		}
+		return &Refreshable[string]{Value: token, IssuedAt: issuedAt, ExpiresAt: expiresAt}, nil
+	}
+}
+
+// jwtLifetimeClaims are the registered JWT claims which determine a token's lifetime.
+type jwtLifetimeClaims struct {
+	IssuedAt  *json.Number `json:"iat"`
+	ExpiresAt *json.Number `json:"exp"`
+}
+
+// parseJWTLifetime returns the issued-at (or the zero time, if not
+// present) and expiry times of a JWT, without verifying its signature.
+func parseJWTLifetime(token string) (time.Time, time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, time.Time{}, errors.New("token does not have three parts")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to decode payload: %v", err)
+	}
+	var claims jwtLifetimeClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to decode claims: %v", err)
+	}
+	if claims.ExpiresAt == nil {
+		return time.Time{}, time.Time{}, errors.New("token has no exp claim")
+	}
+	expiresAt, err := numericDate(*claims.ExpiresAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid exp claim: %v", err)
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		if issuedAt, err = numericDate(*claims.IssuedAt); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid iat claim: %v", err)
+		}
+	}
+	return issuedAt, expiresAt, nil
+}
+
+// numericDate converts a JWT NumericDate (seconds since the
+// Unix epoch, possibly fractional) to a time.Time.
+func numericDate(n json.Number) (time.Time, error) {
+	seconds, err := n.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
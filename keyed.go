@@ -0,0 +1,258 @@
+package refresh
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyedRefreshFunc returns a new value for the given key as well as when it expires.
+type KeyedRefreshFunc[K comparable, V any] func(context.Context, K) (*Refreshable[V], error)
+
+// MapRefresher represents an entity in charge of maintaining many expiring values, keyed
+// by K, "fresh" (e.g. per-tenant tokens or per-region certificates). The values for each
+// key are maintained by an underlying Refresher which is created on first use of the key.
+type MapRefresher[K comparable, V any] interface {
+	// Get returns the current value for the given key if it is not expired. The first
+	// call for a key creates its underlying Refresher and waits for its initial value
+	// (or for the given context to be done). Concurrent first calls for the same key
+	// share a single underlying Refresher and its initialization.
+	//
+	// If the initial value for a key cannot be acquired, the returned error is a
+	// *KeyError and the key's underlying Refresher is discarded, such that the next
+	// call for the key starts over.
+	Get(ctx context.Context, key K) (*Refreshable[V], error)
+
+	// Keys returns the keys which currently have an underlying Refresher, in no particular order.
+	Keys() []K
+
+	// Delete stops and removes the underlying Refresher for the given key, if any, such that
+	// the next call to Get for the key starts over. It returns false if there was none.
+	Delete(key K) bool
+
+	// Stop stops all underlying Refreshers.
+	Stop()
+}
+
+// KeyError is returned by a MapRefresher when the value for a key cannot be acquired.
+type KeyError[K comparable] struct {
+	Key K
+	Err error
+}
+
+// Error returns the error's message.
+func (e *KeyError[K]) Error() string {
+	return fmt.Sprintf("failed to acquire value for key %v: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *KeyError[K]) Unwrap() error {
+	return e.Err
+}
+
+// MapOption represents a map refresher configuration option.
+type MapOption[K comparable, V any] func(*mapRefresher[K, V])
+
+// WithRefresherOptions is the map refresher Option to set the Option(s)
+// with which every underlying Refresher is created.
+func WithRefresherOptions[K comparable, V any](opts ...Option[V]) MapOption[K, V] {
+	return func(m *mapRefresher[K, V]) { m.opts = append(m.opts, opts...) }
+}
+
+// WithKeyOptions is the map refresher Option to set a function returning additional Option(s)
+// for the underlying Refresher of each key, e.g. a per-key RefreshStrategy. These are applied
+// after those set with WithRefresherOptions, such that they take precedence.
+func WithKeyOptions[K comparable, V any](keyOpts func(K) []Option[V]) MapOption[K, V] {
+	return func(m *mapRefresher[K, V]) { m.keyOpts = keyOpts }
+}
+
+// WithIdleTimeout is the map refresher Option to stop and remove the underlying Refresher of
+// keys for which Get was not called for the given duration, such that values which are no longer
+// in use (e.g. those of departed tenants) are not refreshed forever. The next call to Get for an
+// evicted key starts over. Keys are not evicted while calls to Get for them are in progress.
+// By default, underlying Refreshers are kept until Stop is called.
+func WithIdleTimeout[K comparable, V any](idleTimeout time.Duration) MapOption[K, V] {
+	return func(m *mapRefresher[K, V]) { m.idleTimeout = idleTimeout }
+}
+
+// mapRefresher is the private, default implementation of the MapRefresher interface.
+type mapRefresher[K comparable, V any] struct {
+	sync.Mutex
+
+	refreshers map[K]*mapEntry[V]
+	stopped    bool
+	stopEvict  context.CancelFunc
+
+	refreshFunc KeyedRefreshFunc[K, V]
+	opts        []Option[V]
+	keyOpts     func(K) []Option[V]
+	idleTimeout time.Duration
+}
+
+// mapEntry is the underlying Refresher of a key alongside when it was last used.
+type mapEntry[V any] struct {
+	refresher *refresher[V]
+	lastUsed  atomic.Int64 // unix nanoseconds
+	users     int          // calls to Get in progress, guarded by the map refresher's lock
+}
+
+// NewMapRefresher returns a MapRefresher initialized with the given KeyedRefreshFunc and MapOption(s).
+func NewMapRefresher[K comparable, V any](refreshFunc KeyedRefreshFunc[K, V], opts ...MapOption[K, V]) MapRefresher[K, V] {
+	m := &mapRefresher[K, V]{
+		refreshers:  make(map[K]*mapEntry[V]),
+		refreshFunc: refreshFunc,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stopEvict = cancel
+	if m.idleTimeout > 0 {
+		go m.evictIdle(ctx)
+	}
+	return m
+}
+
+// Get returns the current value for the given key if it is not expired.
+func (m *mapRefresher[K, V]) Get(ctx context.Context, key K) (*Refreshable[V], error) {
+	entry, err := m.acquire(key)
+	if err != nil {
+		return nil, &KeyError[K]{Key: key, Err: err}
+	}
+	defer m.release(entry)
+
+	r := entry.refresher
+	if err := r.waitForInitialization(ctx); err != nil {
+		// the key is only discarded once its initialization has failed for good,
+		// i.e. after any initial retries, rather than if the caller gave up first
+		if ctx.Err() == nil {
+			m.discard(key, r)
+		}
+		return nil, &KeyError[K]{Key: key, Err: err}
+	}
+	current, err := r.GetFresh(ctx)
+	if err != nil {
+		return nil, &KeyError[K]{Key: key, Err: err}
+	}
+	return current, nil
+}
+
+// Keys returns the keys which currently have an underlying refresher.
+func (m *mapRefresher[K, V]) Keys() []K {
+	m.Lock()
+	defer m.Unlock()
+
+	keys := make([]K, 0, len(m.refreshers))
+	for key := range m.refreshers {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Delete stops and removes the underlying refresher for a key.
+func (m *mapRefresher[K, V]) Delete(key K) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	entry, ok := m.refreshers[key]
+	if !ok {
+		return false
+	}
+	entry.refresher.Stop()
+	delete(m.refreshers, key)
+	return true
+}
+
+// Stop stops all underlying refreshers.
+func (m *mapRefresher[K, V]) Stop() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.stopped = true
+	m.stopEvict()
+	for key, entry := range m.refreshers {
+		entry.refresher.Stop()
+		delete(m.refreshers, key)
+	}
+}
+
+// acquire returns the entry of a key, creating its underlying refresher if necessary, and
+// marks it as in use (such that it is not evicted) until it is released. Creating a
+// refresher never blocks, so holding the lock while doing so is cheap.
+func (m *mapRefresher[K, V]) acquire(key K) (*mapEntry[V], error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.stopped {
+		return nil, ErrStopped
+	}
+	now := time.Now().UnixNano()
+	if entry, ok := m.refreshers[key]; ok {
+		entry.lastUsed.Store(now)
+		entry.users++
+		return entry, nil
+	}
+	opts := m.opts
+	if m.keyOpts != nil {
+		opts = append(append([]Option[V]{}, m.opts...), m.keyOpts(key)...)
+	}
+	entry := &mapEntry[V]{
+		refresher: NewRefresher(func(ctx context.Context) (*Refreshable[V], error) {
+			return m.refreshFunc(ctx, key)
+		}, opts...).(*refresher[V]),
+	}
+	entry.lastUsed.Store(now)
+	entry.users++
+	m.refreshers[key] = entry
+	return entry, nil
+}
+
+// release marks an entry acquired with acquire as no longer in use by the caller,
+// such that its idle time counts from now.
+func (m *mapRefresher[K, V]) release(entry *mapEntry[V]) {
+	m.Lock()
+	defer m.Unlock()
+
+	entry.users--
+	entry.lastUsed.Store(time.Now().UnixNano())
+}
+
+// discard stops and removes the underlying refresher for a key, if it is the given one.
+func (m *mapRefresher[K, V]) discard(key K, r *refresher[V]) {
+	m.Lock()
+	defer m.Unlock()
+
+	if entry, ok := m.refreshers[key]; ok && entry.refresher == r {
+		r.Stop()
+		delete(m.refreshers, key)
+	}
+}
+
+// evictIdle periodically stops and removes the underlying refreshers
+// of idle keys, until the given context is done.
+func (m *mapRefresher[K, V]) evictIdle(ctx context.Context) {
+	interval := m.idleTimeout / 2
+	if interval <= 0 {
+		interval = m.idleTimeout
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.Lock()
+			for key, entry := range m.refreshers {
+				if entry.users == 0 && now.Sub(time.Unix(0, entry.lastUsed.Load())) >= m.idleTimeout {
+					entry.refresher.Stop()
+					delete(m.refreshers, key)
+				}
+			}
+			m.Unlock()
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapRefresherGetWaitsForInitialRetries(t *testing.T) {
+	var attempts atomic.Int64
+	m := NewMapRefresher(
+		func(_ context.Context, key string) (*Refreshable[string], error) {
+			if attempts.Add(1) == 1 {
+				return nil, errors.New("transient failure")
+			}
+			now := time.Now()
+			return &Refreshable[string]{Value: key, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+		},
+		WithRefresherOptions[string](WithInitialRetry[string](3, 10*time.Millisecond)),
+	)
+	defer m.Stop()
+
+	value, err := m.Get(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("failed to get value: %v", err)
+	}
+	if value.Value != "key" {
+		t.Errorf("got %q, expected %q", value.Value, "key")
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("refresh function called %d times, expected 2", n)
+	}
+}
+
+func TestMapRefresherGetDiscardsFailedKeys(t *testing.T) {
+	errUnknown := errors.New("unknown key")
+	m := NewMapRefresher(
+		func(context.Context, string) (*Refreshable[string], error) { return nil, errUnknown },
+		WithRefresherOptions[string](WithInitialRetry[string](2, time.Millisecond)),
+	)
+	defer m.Stop()
+
+	_, err := m.Get(context.Background(), "key")
+	var keyErr *KeyError[string]
+	if !errors.As(err, &keyErr) || !errors.Is(err, errUnknown) {
+		t.Fatalf("got error %v, expected a *KeyError wrapping the refresh error", err)
+	}
+	if keys := m.Keys(); len(keys) != 0 {
+		t.Errorf("failed key was not discarded, keys: %v", keys)
+	}
+}
+
+func TestMapRefresherGetKeepsKeysOnCallerTimeout(t *testing.T) {
+	release := make(chan struct{})
+	m := NewMapRefresher(func(ctx context.Context, key string) (*Refreshable[string], error) {
+		<-release
+		now := time.Now()
+		return &Refreshable[string]{Value: key, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+	})
+	defer m.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Get(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, expected the context's error", err)
+	}
+	if keys := m.Keys(); len(keys) != 1 {
+		t.Errorf("key still initializing was discarded, keys: %v", keys)
+	}
+	close(release)
+	if _, err := m.Get(context.Background(), "key"); err != nil {
+		t.Errorf("failed to get value: %v", err)
+	}
+}
+
+func TestMapRefresherDoesNotEvictKeysInUse(t *testing.T) {
+	const idleTimeout = 20 * time.Millisecond
+	m := NewMapRefresher(func(ctx context.Context, key string) (*Refreshable[string], error) {
+		time.Sleep(10 * idleTimeout) // an initialization outlasting the idle timeout
+		now := time.Now()
+		return &Refreshable[string]{Value: key, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+	}, WithIdleTimeout[string, string](idleTimeout))
+	defer m.Stop()
+
+	if _, err := m.Get(context.Background(), "key"); err != nil {
+		t.Fatalf("failed to get value: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(m.Keys()) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("idle key was not evicted")
+		}
+		time.Sleep(idleTimeout)
+	}
+}
@@ -0,0 +1,48 @@
+package refresh
+
+import "context"
+
+// WithLazyStart is the refresher Option to defer the refresher's background go-routine, and
+// thus its initial storage read and refresh, until the first call to any of its Get methods
+// (other than GetCurrentUnsafe, which never starts it), ForceRefresh, or WaitForInitialValue.
+// This avoids fetching values which a given process may never use, e.g. to save quota on the
+// upstream issuer.
+func WithLazyStart[T any]() Option[T] {
+	return func(r *refresher[T]) { r.lazyStart = true }
+}
+
+// ensureStarted starts the refresher's background go-routine, unless it is already started.
+// A refresher stopped before it started reports its stop cause as its initialization result.
+func (r *refresher[T]) ensureStarted() {
+	r.startOnce.Do(func() {
+		r.routinesMu.RLock()
+		defer r.routinesMu.RUnlock()
+
+		if r.refreshCtx.Err() != nil {
+			r.initErr = r.wrapErr(ErrStopped, context.Cause(r.refreshCtx))
+			close(r.initialized)
+			return
+		}
+		r.started.Store(true)
+		r.startRunners()
+		r.spawn(func() { r.start(r.refreshCtx) })
+	})
+}
+
+// starting is implemented by Refreshers which may be started lazily.
+type starting interface {
+	isStarted() bool
+}
+
+// Started returns false if the given Refresher was created with WithLazyStart and has not been
+// started yet, i.e. none of the methods which start it have been called. It does not start the
+// Refresher, which allows status reporters to tell idle Refreshers from failing ones.
+func Started[T any](r Refresher[T]) bool {
+	starting, ok := r.(starting)
+	return !ok || starting.isStarted()
+}
+
+// isStarted returns true if the refresher's background go-routine was started.
+func (r *refresher[T]) isStarted() bool {
+	return r.started.Load()
+}
@@ -0,0 +1,31 @@
+package refresh
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger is the refresher Option to set a structured logger to which the refresher logs
+// its lifecycle events (initialization, storage hits and misses, scheduled refreshes, failures
+// and retries, etc). Values themselves are never logged, only their summaries if the refresher
+// has a Redactor (see WithRedactor). By default nothing is logged.
+func WithLogger[T any](logger *slog.Logger) Option[T] {
+	return func(r *refresher[T]) { r.logger = logger }
+}
+
+// log logs a message at the given level, if the refresher has a logger.
+func (r *refresher[T]) log(level slog.Level, msg string, args ...any) {
+	if r.logger == nil {
+		return
+	}
+	if r.name != "" {
+		args = append(args, "refresher", r.name)
+	}
+	r.logger.Log(context.Background(), level, msg, args...)
+}
+
+// logs returns true if the refresher logs messages at the given level, such that
+// hot paths can skip building (allocating) log attributes when nothing is logged.
+func (r *refresher[T]) logs(level slog.Level) bool {
+	return r.logger != nil && r.logger.Enabled(context.Background(), level)
+}
@@ -0,0 +1,316 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Manager is a registry of named Refreshers (of any value type) for services which maintain
+// many expiring values (tokens, certificates, signing keys, etc), providing aggregate lifecycle
+// control and health reporting. Refreshers are added to a Manager with Register.
+//
+// A Manager is safe for concurrent use. The zero value is not usable, use NewManager.
+type Manager struct {
+	mu         sync.RWMutex
+	names      []string
+	refreshers map[string]*managedRefresher
+}
+
+// managedRefresher is a Refresher registered in a Manager, with its value type erased.
+type managedRefresher struct {
+	name                  string
+	waitForInitialization func(context.Context) error
+	stop                  func()
+	stopAndWait           func(context.Context) error
+	status                func() Status
+}
+
+// Status is a summary of the state of a Refresher, which does not include its value.
+type Status struct {
+	// Name is the name under which the Refresher is registered.
+	Name string
+
+	// Started is false if the Refresher was created with WithLazyStart and has
+	// not been used yet, in which case it is not expected to hold a value.
+	Started bool
+
+	// HasValue is true if the Refresher holds a value (which may be expired).
+	HasValue bool
+
+	// IssuedAt and ExpiresAt are the timestamps of the current value, if any.
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+
+	// NextRefreshAt is the time at which the value will be refreshed next.
+	NextRefreshAt time.Time
+
+	// Stopped is true if the Refresher has stopped, for the reason in Cause.
+	Stopped bool
+	Cause   error
+
+	// Stats are counters about the Refresher's refresh attempts.
+	Stats Stats
+
+	// Summary is a summary of the current value, as returned by the function set
+	// with WithValueSummary when registering the Refresher, or otherwise as rendered
+	// by the Refresher's Redactor (see WithRedactor). It is nil if there is neither.
+	Summary any
+}
+
+// Err returns nil if the status is healthy i.e. the Refresher holds an unexpired value (or
+// has not been started yet) and has not stopped. Otherwise it returns an error describing
+// why the status is unhealthy.
+func (s Status) Err() error {
+	switch {
+	case s.Stopped:
+		cause := s.Cause
+		if cause == ErrStopped {
+			cause = nil
+		}
+		return &Error{Name: s.Name, Sentinel: ErrStopped, Cause: cause}
+	case s.Stats.Exhausted:
+		return &Error{Name: s.Name, Sentinel: ErrRefreshFailed, Cause: fmt.Errorf(
+			"gave up after %d consecutive failures: %v", s.Stats.ConsecutiveFailures, s.Stats.LastError)}
+	case !s.Started:
+		return nil
+	case !s.HasValue:
+		return &Error{Name: s.Name, Sentinel: ErrNoValue}
+	case time.Now().After(s.ExpiresAt):
+		return &Error{Name: s.Name, Sentinel: ErrStale}
+	default:
+		return nil
+	}
+}
+
+// NewManager returns an empty Manager.
+func NewManager() *Manager {
+	return &Manager{refreshers: make(map[string]*managedRefresher)}
+}
+
+// RegisterOption represents a Manager registration option.
+type RegisterOption[T any] func(*registration[T])
+
+// registration is the configuration of a Refresher registered in a Manager.
+type registration[T any] struct {
+	summarize func(*Refreshable[T]) any
+}
+
+// WithValueSummary is the RegisterOption to set a function which summarizes the current
+// value of a Refresher for Status (and thus for StatusHandler), e.g. a certificate's subject
+// and serial number or a redacted token. Values are never included in Status otherwise.
+func WithValueSummary[T any](summarize func(*Refreshable[T]) any) RegisterOption[T] {
+	return func(reg *registration[T]) { reg.summarize = summarize }
+}
+
+// Register adds a Refresher to a Manager under the given name,
+// which must not already be in use within the Manager.
+func Register[T any](m *Manager, name string, r Refresher[T], opts ...RegisterOption[T]) error {
+	reg := &registration[T]{}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	managed := &managedRefresher{
+		name:                  name,
+		waitForInitialization: func(ctx context.Context) error { return waitForInitialization(ctx, r) },
+		stop:                  r.Stop,
+		stopAndWait:           r.StopAndWait,
+		status: func() Status {
+			status := Status{Name: name, Started: Started(r), NextRefreshAt: r.GetNextRefreshTime(), Stats: r.Stats()}
+			if current := r.GetCurrentUnsafe(); current != nil {
+				status.HasValue = true
+				status.IssuedAt = current.IssuedAt
+				status.ExpiresAt = current.ExpiresAt
+				if reg.summarize != nil {
+					status.Summary = reg.summarize(current)
+				} else if summary, ok := Redact(r, current); ok {
+					status.Summary = summary
+				}
+			}
+			select {
+			case <-r.Done():
+				status.Stopped = true
+				status.Cause = r.Cause()
+			default:
+			}
+			return status
+		},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.refreshers[name]; ok {
+		return fmt.Errorf("a refresher named %q is already registered", name)
+	}
+	m.names = append(m.names, name)
+	m.refreshers[name] = managed
+	return nil
+}
+
+// Unregister removes the Refresher with the given name from the Manager, without stopping
+// it. It returns false if there is no Refresher registered under the given name.
+func (m *Manager) Unregister(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.refreshers[name]; !ok {
+		return false
+	}
+	delete(m.refreshers, name)
+	for i, n := range m.names {
+		if n == name {
+			m.names = append(m.names[:i:i], m.names[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Names returns the names of all registered Refreshers, in the order in which they were registered.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]string(nil), m.names...)
+}
+
+// Status returns the Status of the Refresher with the given name,
+// or false if there is no Refresher registered under the given name.
+func (m *Manager) Status(name string) (Status, bool) {
+	m.mu.RLock()
+	managed, ok := m.refreshers[name]
+	m.mu.RUnlock()
+
+	if !ok {
+		return Status{}, false
+	}
+	return managed.status(), true
+}
+
+// Range calls the given function with the Status of every registered Refresher, in the order
+// in which they were registered, until the function returns false.
+func (m *Manager) Range(fn func(Status) bool) {
+	for _, managed := range m.snapshot() {
+		if !fn(managed.status()) {
+			return
+		}
+	}
+}
+
+// Statuses returns the Status of every registered Refresher, in the order in which they were registered.
+func (m *Manager) Statuses() []Status {
+	statuses := []Status{}
+	m.Range(func(status Status) bool {
+		statuses = append(statuses, status)
+		return true
+	})
+	return statuses
+}
+
+// Healthy returns nil if every registered Refresher is healthy (as per Status.Err).
+// Otherwise it returns the errors of all unhealthy Refreshers, joined.
+func (m *Manager) Healthy() error {
+	errs := []error{}
+	m.Range(func(status Status) bool {
+		if err := status.Err(); err != nil {
+			errs = append(errs, err)
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// WaitForAll waits for every registered Refresher to load its initial value, or for the given
+// context to be done, whichever happens first. If any Refresher fails to load its initial value,
+// the errors of all failed Refreshers are returned, joined.
+func (m *Manager) WaitForAll(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // releases the waiting go-routines if ctx is done first
+
+	refreshers := m.snapshot()
+	results := make(chan error, len(refreshers))
+	for _, managed := range refreshers {
+		go func() {
+			if err := managed.waitForInitialization(ctx); err != nil {
+				results <- fmt.Errorf("refresher %q: %v", managed.name, err)
+				return
+			}
+			results <- nil
+		}()
+	}
+
+	errs := []error{}
+	for range refreshers {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-results:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// initializing is implemented by Refreshers which can wait for their initialization
+// until a context is done, rather than for a fixed amount of time.
+type initializing interface {
+	waitForInitialization(ctx context.Context) error
+}
+
+// initializationPollInterval is how long each wait lasts when waiting for the initialization
+// of Refreshers which do not implement initializing, such that waiting stops soon after the
+// context is done.
+const initializationPollInterval = 100 * time.Millisecond
+
+// waitForInitialization waits for the given Refresher to have a value, returning its
+// initialization error if it has none once initialized, or the context's error if it is done first.
+func waitForInitialization[T any](ctx context.Context, r Refresher[T]) error {
+	if initializing, ok := r.(initializing); ok {
+		return initializing.waitForInitialization(ctx)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.WaitForInitialValue(initializationPollInterval); !errors.Is(err, ErrInitTimeout) {
+			return err
+		}
+	}
+}
+
+// StopAll stops every registered Refresher. It does not wait for their go-routines to exit.
+func (m *Manager) StopAll() {
+	for _, managed := range m.snapshot() {
+		managed.stop()
+	}
+}
+
+// StopAllAndWait stops every registered Refresher and waits for their go-routines to
+// exit, or for the given context to be done, whichever happens first.
+func (m *Manager) StopAllAndWait(ctx context.Context) error {
+	m.StopAll()
+	for _, managed := range m.snapshot() {
+		if err := managed.stopAndWait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot returns the registered Refreshers, in the order in which they were registered.
+func (m *Manager) snapshot() []*managedRefresher {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	refreshers := make([]*managedRefresher, 0, len(m.names))
+	for _, name := range m.names {
+		refreshers = append(refreshers, m.refreshers[name])
+	}
+	return refreshers
+}
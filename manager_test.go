@@ -0,0 +1,101 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestManagerStatusDoesNotStartLazyRefreshers(t *testing.T) {
+	refreshFunc, calls := counter(time.Hour)
+	r := NewRefresher(refreshFunc, WithLazyStart[int]())
+	defer r.Stop()
+
+	m := NewManager()
+	if err := Register(m, "lazy", r); err != nil {
+		t.Fatal(err)
+	}
+
+	status, ok := m.Status("lazy")
+	if !ok {
+		t.Fatal("refresher not registered")
+	}
+	if status.Started || status.HasValue {
+		t.Errorf("unexpected status before starting: %+v", status)
+	}
+	if err := m.Healthy(); err != nil {
+		t.Errorf("refresher not started yet reported as unhealthy: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := calls.Load(); n != 0 {
+		t.Errorf("refresh function called %d times, expected none", n)
+	}
+
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ = m.Status("lazy"); !status.Started || !status.HasValue {
+		t.Errorf("unexpected status after starting: %+v", status)
+	}
+	if err := m.Healthy(); err != nil {
+		t.Errorf("started refresher reported as unhealthy: %v", err)
+	}
+
+	r.Stop()
+	<-r.Done()
+	if err := m.Healthy(); !errors.Is(err, ErrStopped) {
+		t.Errorf("got %v for a stopped refresher, expected ErrStopped", err)
+	}
+}
+
+// waitCounting is a Refresher which only implements the exported methods of Refresher,
+// and counts the calls to WaitForInitialValue in progress.
+type waitCounting struct {
+	Refresher[int]
+	waiting atomic.Int64
+}
+
+func (w *waitCounting) WaitForInitialValue(timeout time.Duration) error {
+	w.waiting.Add(1)
+	defer w.waiting.Add(-1)
+	return w.Refresher.WaitForInitialValue(timeout)
+}
+
+func TestWaitForAllReturnsWhenContextDone(t *testing.T) {
+	blocked := func(ctx context.Context) (*Refreshable[int], error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	r := NewRefresher(blocked)
+	defer r.Stop()
+	other := &waitCounting{Refresher: NewRefresher(blocked)}
+	defer other.Stop()
+
+	m := NewManager()
+	if err := Register[int](m, "refresher", r); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register[int](m, "other", other); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	began := time.Now()
+	if err := m.WaitForAll(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, expected context.Canceled", err)
+	}
+	if elapsed := time.Since(began); elapsed > time.Second {
+		t.Errorf("WaitForAll returned %s after its context was done", elapsed)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for other.waiting.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("WaitForAll kept waiting for initialization after its context was done")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,141 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Metadata is the state of a refresher which is persisted alongside its value by
+// a MetadataStorage, such that it survives restarts of the application.
+type Metadata struct {
+	// Refreshes, Failures, ConsecutiveFailures, LastRefreshAt, LastSuccessAt,
+	// LastFailureAt, and Exhausted are the refresher's Stats of the same name.
+	Refreshes           int64     `json:"refreshes"`
+	Failures            int64     `json:"failures"`
+	ConsecutiveFailures int64     `json:"consecutive_failures"`
+	LastRefreshAt       time.Time `json:"last_refresh_at"`
+	LastSuccessAt       time.Time `json:"last_success_at"`
+	LastFailureAt       time.Time `json:"last_failure_at"`
+	Exhausted           bool      `json:"exhausted,omitempty"`
+
+	// LastError is the message of the refresher's last refresh error, if any.
+	LastError string `json:"last_error,omitempty"`
+
+	// NextRefreshAt is when the refresher was due to refresh (or to retry a failed refresh) next.
+	NextRefreshAt time.Time `json:"next_refresh_at"`
+}
+
+// MetadataStorage is a Storage which also persists refresher Metadata. When a refresher's
+// Storage is a MetadataStorage, the refresher stores its Metadata after refresh attempts which
+// change its consecutive failures or backoff (i.e. not after every successful refresh), and
+// restores it on initialization: its Stats carry on from where they were, and a refresher
+// which was retrying failed refreshes (without a fresh value to start with) waits until its
+// next retry was due, resuming its backoff rather than hammering the issuer right away. Likewise,
+// a refresher which gave up on background refreshes (see WithMaxConsecutiveFailures) remains
+// exhausted until an explicit refresh succeeds, rather than retrying again after each restart.
+type MetadataStorage[T any] interface {
+	Storage[T]
+
+	// GetMetadata retrieves Metadata.
+	GetMetadata(context.Context) (*Metadata, error)
+
+	// PutMetadata stores Metadata.
+	PutMetadata(context.Context, *Metadata) error
+}
+
+// metadataStorage returns the refresher's Storage as a MetadataStorage, if it is one.
+func (r *refresher[T]) metadataStorage() (MetadataStorage[T], bool) {
+	metadataStorage, ok := r.storage.(MetadataStorage[T])
+	return metadataStorage, ok
+}
+
+// storeMetadata attempts to store the refresher's Metadata, if its Storage is a MetadataStorage
+// and its consecutive failures or backoff changed since its Metadata was last stored (or restored).
+func (r *refresher[T]) storeMetadata(ctx context.Context, nextRefreshAt time.Time) {
+	metadataStorage, ok := r.metadataStorage()
+	if !ok {
+		return
+	}
+	r.metadataMu.Lock()
+	defer r.metadataMu.Unlock()
+
+	stats := r.Stats()
+	if stats.ConsecutiveFailures == r.storedFailures &&
+		(stats.ConsecutiveFailures == 0 || nextRefreshAt.Equal(r.storedNextRefreshAt)) {
+		return // a restarted refresher would behave the same with the stored Metadata
+	}
+	metadata := &Metadata{
+		Refreshes:           stats.Refreshes,
+		Failures:            stats.Failures,
+		ConsecutiveFailures: stats.ConsecutiveFailures,
+		LastRefreshAt:       stats.LastRefreshAt,
+		LastSuccessAt:       stats.LastSuccessAt,
+		LastFailureAt:       stats.LastFailureAt,
+		Exhausted:           stats.Exhausted,
+		NextRefreshAt:       nextRefreshAt,
+	}
+	if stats.LastError != nil {
+		metadata.LastError = stats.LastError.Error()
+	}
+	if err := metadataStorage.PutMetadata(ctx, metadata); err != nil {
+		r.log(slog.LevelWarn, "metadata storage write failed", "error", err)
+		return
+	}
+	r.storedFailures, r.storedNextRefreshAt = metadata.ConsecutiveFailures, metadata.NextRefreshAt
+}
+
+// restoreMetadata attempts to retrieve the refresher's Metadata, if its Storage is a
+// MetadataStorage, restoring its Stats from it. It returns nil if there is no Metadata.
+func (r *refresher[T]) restoreMetadata(ctx context.Context) *Metadata {
+	metadataStorage, ok := r.metadataStorage()
+	if !ok {
+		return nil
+	}
+	metadata, err := metadataStorage.GetMetadata(ctx)
+	if err != nil || metadata == nil {
+		if err != nil {
+			r.log(slog.LevelWarn, "metadata storage read failed", "error", err)
+		}
+		return nil
+	}
+
+	r.statsMu.Lock()
+	r.stats.Refreshes = metadata.Refreshes
+	r.stats.Failures = metadata.Failures
+	r.stats.ConsecutiveFailures = metadata.ConsecutiveFailures
+	r.stats.LastRefreshAt = metadata.LastRefreshAt
+	r.stats.LastSuccessAt = metadata.LastSuccessAt
+	r.stats.LastFailureAt = metadata.LastFailureAt
+	r.stats.Exhausted = metadata.Exhausted && r.maxConsecutiveFailures > 0
+	if metadata.LastError != "" {
+		r.stats.LastError = errors.New(metadata.LastError)
+	}
+	r.statsMu.Unlock()
+
+	r.metadataMu.Lock()
+	r.storedFailures, r.storedNextRefreshAt = metadata.ConsecutiveFailures, metadata.NextRefreshAt
+	r.metadataMu.Unlock()
+
+	r.log(slog.LevelInfo, "restored metadata from storage",
+		"consecutive_failures", metadata.ConsecutiveFailures, "exhausted", metadata.Exhausted, "next_refresh_at", metadata.NextRefreshAt)
+	return metadata
+}
+
+// resumeBackoff waits until the retry of a failed refresh was due as per the given
+// Metadata (if any), or the given context is done, whichever happens first.
+func (r *refresher[T]) resumeBackoff(ctx context.Context, metadata *Metadata) error {
+	if metadata == nil || metadata.ConsecutiveFailures == 0 {
+		return nil
+	}
+	wait := time.Until(metadata.NextRefreshAt)
+	if wait <= 0 {
+		return nil
+	}
+	r.log(slog.LevelInfo, "resuming backoff after failed refreshes", "retry_in", wait)
+	if !sleep(ctx, wait) {
+		return ctx.Err()
+	}
+	return nil
+}
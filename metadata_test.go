@@ -0,0 +1,165 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryMetadataStorage is a MetadataStorage which keeps Metadata in memory and counts writes.
+type memoryMetadataStorage struct {
+	Storage[int]
+	mu       sync.Mutex
+	metadata *Metadata
+	writes   atomic.Int64
+}
+
+func (s *memoryMetadataStorage) GetMetadata(context.Context) (*Metadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.metadata, nil
+}
+
+func (s *memoryMetadataStorage) PutMetadata(_ context.Context, metadata *Metadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadata = metadata
+	s.writes.Add(1)
+	return nil
+}
+
+func TestMetadataStoredOnlyWhenFailuresChange(t *testing.T) {
+	s := &memoryMetadataStorage{Storage: StorageFromFunctions(
+		func(context.Context) (*Refreshable[int], error) { return nil, errors.New("empty") },
+		func(context.Context, *Refreshable[int]) error { return nil },
+	)}
+	var fail atomic.Bool
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(func(ctx context.Context) (*Refreshable[int], error) {
+		if fail.Load() {
+			return nil, errors.New("failure")
+		}
+		return refreshFunc(ctx)
+	}, WithStorage[int](s), WithSynchronousCallbacks[int]())
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	attempt := func() { _, _ = r.ForceRefresh(context.Background()) }
+	for i := 0; i < 3; i++ {
+		attempt()
+	}
+	if n := s.writes.Load(); n != 0 {
+		t.Errorf("metadata written %d times after successful refreshes, expected none", n)
+	}
+
+	fail.Store(true)
+	attempt()
+	attempt()
+	if n := s.writes.Load(); n != 2 {
+		t.Errorf("metadata written %d times after failed refreshes, expected 2", n)
+	}
+
+	fail.Store(false)
+	attempt()
+	attempt()
+	if n := s.writes.Load(); n != 3 {
+		t.Errorf("metadata written %d times after recovering, expected 3", n)
+	}
+	if metadata, _ := s.GetMetadata(context.Background()); metadata.ConsecutiveFailures != 0 {
+		t.Errorf("stored metadata has %d consecutive failures, expected 0", metadata.ConsecutiveFailures)
+	}
+}
+
+func TestMetadataRestoresExhaustion(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		stored *Refreshable[int]
+	)
+	s := &memoryMetadataStorage{Storage: StorageFromFunctions(
+		func(context.Context) (*Refreshable[int], error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if stored == nil {
+				return nil, ErrNotStored
+			}
+			restored := *stored
+			restored.RefreshAt = time.Now().Add(20 * time.Millisecond) // due right after restarting
+			return &restored, nil
+		},
+		func(_ context.Context, refreshable *Refreshable[int]) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored = refreshable
+			return nil
+		},
+	)}
+	var fail atomic.Bool
+	refreshFunc, issued := counter(time.Hour)
+	newRefresher := func() Refresher[int] {
+		return NewRefresher(func(ctx context.Context) (*Refreshable[int], error) {
+			if fail.Load() {
+				return nil, errors.New("failure")
+			}
+			return refreshFunc(ctx)
+		}, WithStorage[int](s), WithMaxConsecutiveFailures[int](2, nil), WithSynchronousCallbacks[int]())
+	}
+
+	// open: the refresher gives up after consecutive failures
+	r := newRefresher()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	fail.Store(true)
+	_, _ = r.ForceRefresh(context.Background())
+	_, _ = r.ForceRefresh(context.Background())
+	if !r.Stats().Exhausted {
+		t.Fatal("refresher not exhausted after consecutive failures")
+	}
+	if err := r.StopAndWait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if metadata, _ := s.GetMetadata(context.Background()); metadata == nil || !metadata.Exhausted {
+		t.Fatalf("stored metadata %+v, expected it to be exhausted", metadata)
+	}
+
+	// remains open after restarting: due background refreshes are not carried out
+	fail.Store(false)
+	r = newRefresher()
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Stats().Exhausted {
+		t.Fatal("exhaustion not restored from metadata")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := issued.Load(); n != 1 {
+		t.Errorf("value issued %d times while exhausted, expected no background refreshes", n)
+	}
+
+	// half-open: explicit refreshes are still carried out, but failures keep the refresher exhausted
+	fail.Store(true)
+	if _, err := r.ForceRefresh(context.Background()); err == nil {
+		t.Fatal("refresh succeeded, expected a failure")
+	}
+	if !r.Stats().Exhausted {
+		t.Error("refresher not exhausted after a failed explicit refresh")
+	}
+
+	// closed: a successful explicit refresh resumes background refreshes
+	fail.Store(false)
+	if _, err := r.ForceRefresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if r.Stats().Exhausted {
+		t.Error("refresher exhausted after a successful explicit refresh")
+	}
+	if metadata, _ := s.GetMetadata(context.Background()); metadata.Exhausted || metadata.ConsecutiveFailures != 0 {
+		t.Errorf("stored metadata %+v, expected it to be reset", metadata)
+	}
+}
@@ -0,0 +1,78 @@
+// Package expvar publishes the state of refreshers as expvar variables, for services which
+// already expose /debug/vars and want refresher metrics without further dependencies:
+//
+//	expvar.Publish("api_token", r)
+//
+// The state is computed whenever the variable is read, so publishing has no ongoing cost.
+package expvar
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// state is the expvar representation of a refresher's state.
+type state struct {
+	Started              bool    `json:"started"`
+	HasValue             bool    `json:"has_value"`
+	IssuedAt             string  `json:"issued_at,omitempty"`
+	ExpiresAt            string  `json:"expires_at,omitempty"`
+	ExpiresInSeconds     float64 `json:"expires_in_seconds,omitempty"`
+	NextRefreshAt        string  `json:"next_refresh_at,omitempty"`
+	NextRefreshInSeconds float64 `json:"next_refresh_in_seconds,omitempty"`
+	Stopped              bool    `json:"stopped"`
+	Refreshes            int64   `json:"refreshes"`
+	Failures             int64   `json:"failures"`
+	ConsecutiveFailures  int64   `json:"consecutive_failures"`
+	LastError            string  `json:"last_error,omitempty"`
+	Exhausted            bool    `json:"exhausted"`
+	Summary              string  `json:"summary,omitempty"`
+}
+
+// Publish publishes the state of the given refresh.Refresher as an expvar variable with the
+// given name: its value's timestamps, its next refresh time, and its refresh counters. The
+// value itself is never published, only its summary if the refresher has a refresh.Redactor.
+// Reading the variable does not start refreshers created with refresh.WithLazyStart.
+//
+// Like expvar.Publish, it panics if a variable with the given name is already published.
+func Publish[T any](name string, r refresh.Refresher[T]) {
+	expvar.Publish(name, Func(r))
+}
+
+// Func returns an expvar.Func reporting the state of the given refresh.Refresher,
+// for publishing under a custom expvar.Map rather than at the top level.
+func Func[T any](r refresh.Refresher[T]) expvar.Func {
+	return func() any {
+		now := time.Now()
+		stats := r.Stats()
+		s := &state{
+			Started:             refresh.Started(r),
+			Refreshes:           stats.Refreshes,
+			Failures:            stats.Failures,
+			ConsecutiveFailures: stats.ConsecutiveFailures,
+			Exhausted:           stats.Exhausted,
+		}
+		if current := r.GetCurrentUnsafe(); current != nil {
+			s.HasValue = true
+			s.IssuedAt = current.IssuedAt.Format(time.RFC3339Nano)
+			s.ExpiresAt = current.ExpiresAt.Format(time.RFC3339Nano)
+			s.ExpiresInSeconds = current.ExpiresAt.Sub(now).Seconds()
+			s.Summary, _ = refresh.Redact(r, current)
+		}
+		if nextRefreshAt := r.GetNextRefreshTime(); !nextRefreshAt.IsZero() {
+			s.NextRefreshAt = nextRefreshAt.Format(time.RFC3339Nano)
+			s.NextRefreshInSeconds = nextRefreshAt.Sub(now).Seconds()
+		}
+		if stats.LastError != nil {
+			s.LastError = stats.LastError.Error()
+		}
+		select {
+		case <-r.Done():
+			s.Stopped = true
+		default:
+		}
+		return s
+	}
+}
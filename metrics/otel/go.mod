@@ -0,0 +1,17 @@
+module github.com/adrianosela/refresh/metrics/otel
+
+go 1.22
+
+require (
+	github.com/adrianosela/refresh v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.32.0
+	go.opentelemetry.io/otel/metric v1.32.0
+	go.opentelemetry.io/otel/trace v1.32.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+)
+
+replace github.com/adrianosela/refresh => ../..
@@ -0,0 +1,205 @@
+// Package otel provides OpenTelemetry tracing and metrics instrumentation for refreshers.
+//
+// Refreshes and storage operations are traced by wrapping the RefreshFunc and Storage,
+// such that the span's context is propagated to any downstream (e.g. HTTP or gRPC) calls
+// made by them. Metrics are recorded by a refresh.Observer:
+//
+//	r := refresh.NewRefresher(
+//		otel.RefreshFunc("api-token", refreshFunc),
+//		refresh.WithStorage(otel.Storage("api-token", storage)),
+//		refresh.WithObserver(otel.MustNewObserver[Token]("api-token")),
+//	)
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	globalotel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/adrianosela/refresh"
+)
+
+// instrumentationName is the name of this instrumentation library.
+const instrumentationName = "github.com/adrianosela/refresh/metrics/otel"
+
+const (
+	attrRefresher = attribute.Key("refresh.refresher")
+	attrResult    = attribute.Key("refresh.result")
+)
+
+// Option represents an instrumentation configuration option.
+type Option func(*config)
+
+// config is the configuration of the instrumentation.
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider is the Option to override the default (global) trace.TracerProvider.
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(c *config) { c.tracerProvider = tracerProvider }
+}
+
+// WithMeterProvider is the Option to override the default (global) metric.MeterProvider.
+func WithMeterProvider(meterProvider metric.MeterProvider) Option {
+	return func(c *config) { c.meterProvider = meterProvider }
+}
+
+// newConfig returns the configuration resulting from applying the given Option(s).
+func newConfig(opts []Option) *config {
+	c := &config{
+		tracerProvider: globalotel.GetTracerProvider(),
+		meterProvider:  globalotel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// RefreshFunc wraps the given refresh.RefreshFunc such that every invocation runs
+// within a span, the context of which is passed on to the wrapped function.
+func RefreshFunc[T any](name string, refreshFunc refresh.RefreshFunc[T], opts ...Option) refresh.RefreshFunc[T] {
+	tracer := newConfig(opts).tracerProvider.Tracer(instrumentationName)
+	return func(ctx context.Context) (*refresh.Refreshable[T], error) {
+		ctx, span := tracer.Start(ctx, "refresh", trace.WithAttributes(attrRefresher.String(name)))
+		defer span.End()
+
+		refreshable, err := refreshFunc(ctx)
+		if err != nil {
+			endWithError(span, err)
+			return nil, err
+		}
+		if refreshable != nil {
+			span.SetAttributes(
+				attribute.String("refresh.issued_at", refreshable.IssuedAt.String()),
+				attribute.String("refresh.expires_at", refreshable.ExpiresAt.String()),
+			)
+		}
+		return refreshable, nil
+	}
+}
+
+// storage is a refresh.Storage which traces the operations of an inner Storage.
+type storage[T any] struct {
+	name   string
+	inner  refresh.Storage[T]
+	tracer trace.Tracer
+}
+
+// Storage wraps the given refresh.Storage such that every operation runs
+// within a span, the context of which is passed on to the wrapped Storage.
+func Storage[T any](name string, inner refresh.Storage[T], opts ...Option) refresh.Storage[T] {
+	return &storage[T]{
+		name:   name,
+		inner:  inner,
+		tracer: newConfig(opts).tracerProvider.Tracer(instrumentationName),
+	}
+}
+
+// Get retrieves a Refreshable from the inner Storage within a span.
+func (s *storage[T]) Get(ctx context.Context) (*refresh.Refreshable[T], error) {
+	ctx, span := s.tracer.Start(ctx, "refresh.storage.get", trace.WithAttributes(attrRefresher.String(s.name)))
+	defer span.End()
+
+	refreshable, err := s.inner.Get(ctx)
+	if err != nil {
+		endWithError(span, err)
+	}
+	return refreshable, err
+}
+
+// Put stores a Refreshable in the inner Storage within a span.
+func (s *storage[T]) Put(ctx context.Context, refreshable *refresh.Refreshable[T]) error {
+	ctx, span := s.tracer.Start(ctx, "refresh.storage.put", trace.WithAttributes(attrRefresher.String(s.name)))
+	defer span.End()
+
+	err := s.inner.Put(ctx, refreshable)
+	if err != nil {
+		endWithError(span, err)
+	}
+	return err
+}
+
+// endWithError records an error on a span.
+func endWithError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// observer is a refresh.Observer which records OpenTelemetry metrics.
+type observer[T any] struct {
+	name             string
+	refreshes        metric.Int64Counter
+	refreshDurations metric.Float64Histogram
+	storageReads     metric.Int64Counter
+	storageWrites    metric.Int64Counter
+}
+
+// NewObserver returns a refresh.Observer which records metrics
+// about a refresher, under the given refresher name.
+func NewObserver[T any](name string, opts ...Option) (refresh.Observer[T], error) {
+	meter := newConfig(opts).meterProvider.Meter(instrumentationName)
+
+	o := &observer[T]{name: name}
+	var err error
+	if o.refreshes, err = meter.Int64Counter("refresh.refreshes",
+		metric.WithDescription("Number of refresh attempts by result.")); err != nil {
+		return nil, fmt.Errorf("failed to create refreshes counter: %v", err)
+	}
+	if o.refreshDurations, err = meter.Float64Histogram("refresh.refresh.duration",
+		metric.WithDescription("Duration of refresh attempts."), metric.WithUnit("s")); err != nil {
+		return nil, fmt.Errorf("failed to create refresh duration histogram: %v", err)
+	}
+	if o.storageReads, err = meter.Int64Counter("refresh.storage.reads",
+		metric.WithDescription("Number of storage reads by result.")); err != nil {
+		return nil, fmt.Errorf("failed to create storage reads counter: %v", err)
+	}
+	if o.storageWrites, err = meter.Int64Counter("refresh.storage.writes",
+		metric.WithDescription("Number of storage writes by result.")); err != nil {
+		return nil, fmt.Errorf("failed to create storage writes counter: %v", err)
+	}
+	return o, nil
+}
+
+// MustNewObserver is like NewObserver but panics if the instruments cannot be created.
+func MustNewObserver[T any](name string, opts ...Option) refresh.Observer[T] {
+	o, err := NewObserver[T](name, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return o
+}
+
+// ObserveRefresh records the outcome of a refresh attempt.
+func (o *observer[T]) ObserveRefresh(observation refresh.RefreshObservation[T]) {
+	attrs := metric.WithAttributes(attrRefresher.String(o.name), attrResult.String(resultOf(observation.Err)))
+	o.refreshes.Add(context.Background(), 1, attrs)
+	o.refreshDurations.Record(context.Background(), observation.Duration.Seconds(), attrs)
+}
+
+// ObserveStorageRead records the outcome of a reading from storage.
+func (o *observer[T]) ObserveStorageRead(observation refresh.StorageObservation[T]) {
+	o.storageReads.Add(context.Background(), 1,
+		metric.WithAttributes(attrRefresher.String(o.name), attrResult.String(resultOf(observation.Err))))
+}
+
+// ObserveStorageWrite records the outcome of a writing to storage.
+func (o *observer[T]) ObserveStorageWrite(observation refresh.StorageObservation[T]) {
+	o.storageWrites.Add(context.Background(), 1,
+		metric.WithAttributes(attrRefresher.String(o.name), attrResult.String(resultOf(observation.Err))))
+}
+
+// resultOf returns the result attribute value for an error.
+func resultOf(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
@@ -0,0 +1,187 @@
+// Package prometheus provides Prometheus instrumentation for refreshers.
+//
+// A single Collector can instrument any number of refreshers, each identified by
+// a user-supplied name which is used as the "refresher" label of all metrics:
+//
+//	collector := prometheus.NewCollector()
+//	registry.MustRegister(collector)
+//
+//	r := refresh.NewRefresher(refreshFunc,
+//		refresh.WithObserver(prometheus.NewObserver[Token](collector, "api-token")))
+package prometheus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/adrianosela/refresh"
+)
+
+const (
+	labelRefresher = "refresher"
+	labelResult    = "result"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+// Option represents a Collector configuration option.
+type Option func(*config)
+
+// config is the configuration of a Collector.
+type config struct {
+	namespace string
+	buckets   []float64
+}
+
+// WithNamespace is the Collector Option to override the default ("refresh") metric namespace.
+func WithNamespace(namespace string) Option {
+	return func(c *config) { c.namespace = namespace }
+}
+
+// WithRefreshDurationBuckets is the Collector Option to override the
+// default (prometheus.DefBuckets) buckets of the refresh duration histogram.
+func WithRefreshDurationBuckets(buckets []float64) Option {
+	return func(c *config) { c.buckets = buckets }
+}
+
+// Collector is a prometheus.Collector of metrics about refreshers.
+type Collector struct {
+	refreshes        *prometheus.CounterVec
+	refreshDurations *prometheus.HistogramVec
+	storageReads     *prometheus.CounterVec
+	storageWrites    *prometheus.CounterVec
+	expiresIn        *prometheus.Desc
+	nextRefreshIn    *prometheus.Desc
+
+	mu            sync.Mutex
+	expiresAt     map[string]time.Time
+	nextRefreshAt map[string]time.Time
+}
+
+// ensure Collector implements prometheus.Collector
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector returns a Collector with the given Option(s).
+func NewCollector(opts ...Option) *Collector {
+	cfg := &config{namespace: "refresh", buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &Collector{
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "refreshes_total",
+			Help:      "Total number of refresh attempts by result.",
+		}, []string{labelRefresher, labelResult}),
+		refreshDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "refresh_duration_seconds",
+			Help:      "Duration of refresh attempts in seconds.",
+			Buckets:   cfg.buckets,
+		}, []string{labelRefresher, labelResult}),
+		storageReads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "storage_reads_total",
+			Help:      "Total number of storage reads by result.",
+		}, []string{labelRefresher, labelResult}),
+		storageWrites: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "storage_writes_total",
+			Help:      "Total number of storage writes by result.",
+		}, []string{labelRefresher, labelResult}),
+		expiresIn: prometheus.NewDesc(
+			prometheus.BuildFQName(cfg.namespace, "", "expires_in_seconds"),
+			"Seconds until the current value expires (negative if already expired).",
+			[]string{labelRefresher}, nil,
+		),
+		nextRefreshIn: prometheus.NewDesc(
+			prometheus.BuildFQName(cfg.namespace, "", "next_refresh_in_seconds"),
+			"Seconds until the next scheduled refresh (negative if overdue).",
+			[]string{labelRefresher}, nil,
+		),
+		expiresAt:     make(map[string]time.Time),
+		nextRefreshAt: make(map[string]time.Time),
+	}
+}
+
+// Describe sends the descriptors of all metrics collected by the Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.refreshes.Describe(ch)
+	c.refreshDurations.Describe(ch)
+	c.storageReads.Describe(ch)
+	c.storageWrites.Describe(ch)
+	ch <- c.expiresIn
+	ch <- c.nextRefreshIn
+}
+
+// Collect sends all metrics collected by the Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.refreshes.Collect(ch)
+	c.refreshDurations.Collect(ch)
+	c.storageReads.Collect(ch)
+	c.storageWrites.Collect(ch)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for name, expiresAt := range c.expiresAt {
+		ch <- prometheus.MustNewConstMetric(c.expiresIn, prometheus.GaugeValue, expiresAt.Sub(now).Seconds(), name)
+	}
+	for name, nextRefreshAt := range c.nextRefreshAt {
+		ch <- prometheus.MustNewConstMetric(c.nextRefreshIn, prometheus.GaugeValue, nextRefreshAt.Sub(now).Seconds(), name)
+	}
+}
+
+// setCurrent records the expiry and next refresh time of a refresher's current value.
+func (c *Collector) setCurrent(name string, expiresAt, nextRefreshAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expiresAt[name] = expiresAt
+	if !nextRefreshAt.IsZero() {
+		c.nextRefreshAt[name] = nextRefreshAt
+	}
+}
+
+// observer is a refresh.Observer which records metrics in a Collector.
+type observer[T any] struct {
+	collector *Collector
+	name      string
+}
+
+// NewObserver returns a refresh.Observer which records metrics about
+// a refresher in the given Collector, under the given refresher name.
+func NewObserver[T any](collector *Collector, name string) refresh.Observer[T] {
+	return &observer[T]{collector: collector, name: name}
+}
+
+// ObserveRefresh records the outcome of a refresh attempt.
+func (o *observer[T]) ObserveRefresh(observation refresh.RefreshObservation[T]) {
+	result := resultOf(observation.Err)
+	o.collector.refreshes.WithLabelValues(o.name, result).Inc()
+	o.collector.refreshDurations.WithLabelValues(o.name, result).Observe(observation.Duration.Seconds())
+	if observation.Refreshable != nil {
+		o.collector.setCurrent(o.name, observation.Refreshable.ExpiresAt, observation.RefreshAt)
+	}
+}
+
+// ObserveStorageRead records the outcome of a reading from storage.
+func (o *observer[T]) ObserveStorageRead(observation refresh.StorageObservation[T]) {
+	o.collector.storageReads.WithLabelValues(o.name, resultOf(observation.Err)).Inc()
+}
+
+// ObserveStorageWrite records the outcome of a writing to storage.
+func (o *observer[T]) ObserveStorageWrite(observation refresh.StorageObservation[T]) {
+	o.collector.storageWrites.WithLabelValues(o.name, resultOf(observation.Err)).Inc()
+}
+
+// resultOf returns the result label value for an error.
+func resultOf(err error) string {
+	if err != nil {
+		return resultFailure
+	}
+	return resultSuccess
+}
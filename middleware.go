@@ -0,0 +1,37 @@
+package refresh
+
+import "context"
+
+// RefreshMiddleware wraps a RefreshFunc with cross-cutting behavior, e.g. logging, metrics,
+// rate limiting, or retries within a single refresh attempt, the same way HTTP middleware
+// wraps handlers.
+type RefreshMiddleware[T any] func(RefreshFunc[T]) RefreshFunc[T]
+
+// WithMiddleware is the refresher Option to wrap the refresher's RefreshFunc with the given
+// RefreshMiddleware(s). The first middleware is the outermost one, i.e. the first to be called
+// on every refresh attempt. Middleware added with multiple options are chained in order.
+func WithMiddleware[T any](middleware ...RefreshMiddleware[T]) Option[T] {
+	return func(r *refresher[T]) { r.middleware = append(r.middleware, middleware...) }
+}
+
+// previousContextKey is the context key under which the previous value
+// is passed through middleware, which only deal with RefreshFuncs.
+type previousContextKey struct{}
+
+// withMiddleware wraps a RefreshFuncWithPrevious with the refresher's middleware, if any.
+// The chain is built once, such that middleware may keep state across refresh attempts.
+func (r *refresher[T]) withMiddleware(refreshFunc RefreshFuncWithPrevious[T]) RefreshFuncWithPrevious[T] {
+	if len(r.middleware) == 0 {
+		return refreshFunc
+	}
+	var wrapped RefreshFunc[T] = func(ctx context.Context) (*Refreshable[T], error) {
+		previous, _ := ctx.Value(previousContextKey{}).(*Refreshable[T])
+		return refreshFunc(ctx, previous)
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		wrapped = r.middleware[i](wrapped)
+	}
+	return func(ctx context.Context, previous *Refreshable[T]) (*Refreshable[T], error) {
+		return wrapped(context.WithValue(ctx, previousContextKey{}, previous))
+	}
+}
@@ -0,0 +1,220 @@
+// Package notify provides a refresh.Observer which sends refresh events to external sinks
+// (e.g. a webhook routed to Slack or PagerDuty), such that rotation failures reach on-call
+// engineers without callback plumbing in every service:
+//
+//	sink := notify.NewWebhook("https://hooks.example.com/refresh", notify.WithSigningKey(key))
+//
+//	r := refresh.NewRefresher(refreshFunc,
+//		refresh.WithObserver(notify.NewObserver[Token]("api-token", sink, notify.WithFailuresOnly())))
+//
+// Events describe the outcome of operations (timestamps and errors) and never carry values,
+// only their summaries if the refresher has a refresh.Redactor.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// EventType represents the type of an Event.
+type EventType string
+
+const (
+	// EventRefreshSuccess is the type of events sent after a successful refresh.
+	EventRefreshSuccess EventType = "refresh_success"
+
+	// EventRefreshFailure is the type of events sent after a failed refresh.
+	EventRefreshFailure EventType = "refresh_failure"
+
+	// EventStorageWriteFailure is the type of events sent after a failed storage write.
+	EventStorageWriteFailure EventType = "storage_write_failure"
+)
+
+// Event represents a refresh event.
+type Event struct {
+	// Refresher is the name of the refresher.
+	Refresher string `json:"refresher"`
+
+	// Type is the type of the event.
+	Type EventType `json:"type"`
+
+	// Time is when the event occurred.
+	Time time.Time `json:"time"`
+
+	// Duration is how long the operation took.
+	Duration time.Duration `json:"duration_ns"`
+
+	// IssuedAt and ExpiresAt are the new value's timestamps, set for successful refreshes.
+	IssuedAt  *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// NextRefreshAt is the time of the next scheduled refresh, set for successful refreshes.
+	NextRefreshAt *time.Time `json:"next_refresh_at,omitempty"`
+
+	// Summary is the new value's summary, set for successful refreshes of refreshers
+	// with a refresh.Redactor (see refresh.WithRedactor).
+	Summary string `json:"summary,omitempty"`
+
+	// Error is the reason why the operation failed, set for failures.
+	Error string `json:"error,omitempty"`
+}
+
+// Sink represents a destination for refresh events.
+type Sink interface {
+	// Send delivers an event.
+	Send(ctx context.Context, event Event) error
+}
+
+// SinkFunc is a function which implements Sink.
+type SinkFunc func(ctx context.Context, event Event) error
+
+// Send delivers an event by calling the function.
+func (f SinkFunc) Send(ctx context.Context, event Event) error { return f(ctx, event) }
+
+// DefaultSendTimeout is the default time budget for delivering an event (including retries).
+const DefaultSendTimeout = 30 * time.Second
+
+// DefaultQueueSize is the default number of events which can be waiting for delivery.
+const DefaultQueueSize = 64
+
+// ErrQueueFull is reported (see WithOnError) for events dropped because
+// too many events were already waiting for delivery.
+var ErrQueueFull = errors.New("too many events waiting for delivery")
+
+// ObserverOption represents an Observer configuration option.
+type ObserverOption func(*observerConfig)
+
+// observerConfig is the configuration of an Observer.
+type observerConfig struct {
+	failuresOnly bool
+	sendTimeout  time.Duration
+	queueSize    int
+	onError      func(error)
+}
+
+// WithFailuresOnly is the ObserverOption to only send events about failures.
+func WithFailuresOnly() ObserverOption {
+	return func(c *observerConfig) { c.failuresOnly = true }
+}
+
+// WithSendTimeout is the ObserverOption to override the default time budget (DefaultSendTimeout)
+// for delivering an event.
+func WithSendTimeout(timeout time.Duration) ObserverOption {
+	return func(c *observerConfig) { c.sendTimeout = timeout }
+}
+
+// WithQueueSize is the ObserverOption to override the default number of events (DefaultQueueSize)
+// which can be waiting for delivery. Events observed while the queue is full are dropped.
+func WithQueueSize(size int) ObserverOption {
+	return func(c *observerConfig) { c.queueSize = size }
+}
+
+// WithOnError is the ObserverOption to set a callback function to be fired when an event
+// could not be delivered. By default, such errors are dropped.
+func WithOnError(onError func(error)) ObserverOption {
+	return func(c *observerConfig) { c.onError = onError }
+}
+
+// observer is a refresh.Observer which sends events to a Sink.
+type observer[T any] struct {
+	name  string
+	sink  Sink
+	queue chan Event
+	observerConfig
+}
+
+// NewObserver returns a refresh.Observer which sends events about the refresher with the
+// given name to the given Sink. Events are delivered in order by a single go-routine of the
+// refresher (see refresh.Runner), such that slow sinks delay neither the refresher nor its
+// other event handlers, and such that stopping the refresher with StopAndWait waits for the
+// delivery of the events observed before it stopped. The Observer must thus not be shared
+// by multiple refreshers.
+func NewObserver[T any](name string, sink Sink, opts ...ObserverOption) refresh.Observer[T] {
+	o := &observer[T]{
+		name: name,
+		sink: sink,
+		observerConfig: observerConfig{
+			sendTimeout: DefaultSendTimeout,
+			queueSize:   DefaultQueueSize,
+			onError:     func(err error) { /* NOOP */ },
+		},
+	}
+	for _, opt := range opts {
+		opt(&o.observerConfig)
+	}
+	o.queue = make(chan Event, o.queueSize)
+	return o
+}
+
+// Run delivers queued events until the given context is done, which it is once the
+// refresher makes no more observations, and then delivers the events still queued.
+func (o *observer[T]) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-o.queue:
+			o.deliver(event)
+		case <-ctx.Done():
+			for {
+				select {
+				case event := <-o.queue:
+					o.deliver(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// ObserveRefresh sends an event about a refresh attempt.
+func (o *observer[T]) ObserveRefresh(observation refresh.RefreshObservation[T]) {
+	if observation.Err != nil {
+		o.send(Event{Type: EventRefreshFailure, Duration: observation.Duration, Error: observation.Err.Error()})
+		return
+	}
+	if o.failuresOnly || observation.Refreshable == nil {
+		return
+	}
+	o.send(Event{
+		Type:          EventRefreshSuccess,
+		Duration:      observation.Duration,
+		IssuedAt:      &observation.Refreshable.IssuedAt,
+		ExpiresAt:     &observation.Refreshable.ExpiresAt,
+		NextRefreshAt: &observation.RefreshAt,
+		Summary:       observation.Summary,
+	})
+}
+
+// ObserveStorageRead does nothing, storage reads are not notified.
+func (o *observer[T]) ObserveStorageRead(refresh.StorageObservation[T]) {}
+
+// ObserveStorageWrite sends an event about a failed storage write.
+func (o *observer[T]) ObserveStorageWrite(observation refresh.StorageObservation[T]) {
+	if observation.Err == nil {
+		return
+	}
+	o.send(Event{Type: EventStorageWriteFailure, Duration: observation.Duration, Error: observation.Err.Error()})
+}
+
+// send queues an event for delivery to the sink, dropping it if the queue is full.
+func (o *observer[T]) send(event Event) {
+	event.Refresher = o.name
+	event.Time = time.Now()
+	select {
+	case o.queue <- event:
+	default:
+		o.onError(ErrQueueFull)
+	}
+}
+
+// deliver delivers an event to the sink.
+func (o *observer[T]) deliver(event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.sendTimeout)
+	defer cancel()
+	if err := o.sink.Send(ctx, event); err != nil {
+		o.onError(err)
+	}
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+func TestObserverDeliversInOrderBeforeStopped(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		delivered []EventType
+	)
+	sink := SinkFunc(func(_ context.Context, event Event) error {
+		time.Sleep(5 * time.Millisecond) // a slow sink
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, event.Type)
+		return nil
+	})
+
+	fail := false
+	r := refresh.NewRefresher(func(context.Context) (*refresh.Refreshable[int], error) {
+		if fail {
+			return nil, errors.New("issuer unavailable")
+		}
+		now := time.Now()
+		return &refresh.Refreshable[int]{Value: 1, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+	}, refresh.WithObserver(NewObserver[int]("token", sink)))
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	fail = true // refreshes are never concurrent, and happen-after ForceRefresh is called
+	for i := 0; i < 3; i++ {
+		_, _ = r.ForceRefresh(context.Background())
+	}
+	if err := r.StopAndWait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []EventType{EventRefreshSuccess, EventRefreshFailure, EventRefreshFailure, EventRefreshFailure}
+	if len(delivered) != len(expected) {
+		t.Fatalf("delivered %v before the refresher stopped, expected %v", delivered, expected)
+	}
+	for i := range expected {
+		if delivered[i] != expected[i] {
+			t.Fatalf("delivered %v, expected %v", delivered, expected)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader is the header carrying the hex encoded HMAC-SHA256 signature of webhook
+	// requests, prefixed with "sha256=". The signature covers the timestamp header's value, a
+	// period, and the request body, such that receivers can reject replayed requests.
+	SignatureHeader = "Refresh-Signature"
+
+	// TimestampHeader is the header carrying the time (in seconds since the Unix epoch)
+	// at which a webhook request was signed.
+	TimestampHeader = "Refresh-Timestamp"
+)
+
+// WebhookOption represents a webhook configuration option.
+type WebhookOption func(*webhook)
+
+// WithHTTPClient is the WebhookOption to override the default (http.DefaultClient) HTTP client.
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(w *webhook) { w.client = client }
+}
+
+// WithSigningKey is the WebhookOption to sign requests with HMAC-SHA256 using the given key.
+func WithSigningKey(key []byte) WebhookOption {
+	return func(w *webhook) { w.signingKey = key }
+}
+
+// WithHeader is the WebhookOption to set a header on every request (e.g. for authorization).
+func WithHeader(name, value string) WebhookOption {
+	return func(w *webhook) { w.header.Set(name, value) }
+}
+
+// WithRetries is the WebhookOption to retry failed deliveries up to the given number of attempts
+// (in total), waiting the given backoff after the first failed attempt and doubling it after every
+// subsequent one. Only network errors and 429 and 5xx responses are retried. Defaults to 3 attempts
+// with a 1 second backoff.
+func WithRetries(attempts int, backoff time.Duration) WebhookOption {
+	return func(w *webhook) {
+		w.attempts = attempts
+		w.backoff = backoff
+	}
+}
+
+// webhook is a Sink which posts events as JSON to a URL.
+type webhook struct {
+	url        string
+	client     *http.Client
+	signingKey []byte
+	header     http.Header
+	attempts   int
+	backoff    time.Duration
+}
+
+// NewWebhook returns a Sink which posts events as JSON to the given URL.
+func NewWebhook(url string, opts ...WebhookOption) Sink {
+	w := &webhook{
+		url:      url,
+		client:   http.DefaultClient,
+		header:   make(http.Header),
+		attempts: 3,
+		backoff:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Send posts an event to the webhook, retrying failed deliveries.
+func (w *webhook) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	backoff := w.backoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= w.attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// post makes a single delivery attempt, returning whether a failure may be retried.
+func (w *webhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build request: %v", err)
+	}
+	for name, values := range w.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.signingKey != nil {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, "sha256="+Sign(w.signingKey, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to post event: %v", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("failed to post event: unexpected status %s", resp.Status)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of a webhook request with the given
+// timestamp header value and body, for receivers to compare (in constant time, e.g. with
+// hmac.Equal) against the signature header's value.
+func Sign(key []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,118 @@
+package refresh
+
+import (
+	"context"
+	"time"
+)
+
+// Observer represents an entity which observes the outcome of a refresher's operations,
+// e.g. to record metrics. Unlike the individual event handlers, any number of Observers
+// can be set on a refresher, and observations carry the duration of each operation.
+type Observer[T any] interface {
+	// ObserveRefresh is invoked after every refresh attempt.
+	ObserveRefresh(RefreshObservation[T])
+
+	// ObserveStorageRead is invoked after every reading from storage. Retried reads
+	// (see WithStorageReadRetry) are observed once, after the last attempt.
+	ObserveStorageRead(StorageObservation[T])
+
+	// ObserveStorageWrite is invoked after every writing to storage.
+	ObserveStorageWrite(StorageObservation[T])
+}
+
+// RefreshObservation is the outcome of a refresh attempt.
+type RefreshObservation[T any] struct {
+	// Refreshable is the new value, nil if the attempt failed.
+	Refreshable *Refreshable[T]
+
+	// RefreshAt is the time of the next scheduled refresh, zero if the attempt failed.
+	RefreshAt time.Time
+
+	// Summary is the new value's summary, as rendered by the refresher's Redactor
+	// (see WithRedactor). It is empty if the attempt failed or there is no Redactor.
+	Summary string
+
+	// Duration is how long the attempt took.
+	Duration time.Duration
+
+	// Err is the reason why the attempt failed, nil if it succeeded.
+	Err error
+}
+
+// StorageObservation is the outcome of a storage operation.
+type StorageObservation[T any] struct {
+	// Refreshable is the value read or written, nil if the operation failed.
+	Refreshable *Refreshable[T]
+
+	// Summary is the value's summary, as rendered by the refresher's Redactor
+	// (see WithRedactor). It is empty if the operation failed or there is no Redactor.
+	Summary string
+
+	// Duration is how long the operation took.
+	Duration time.Duration
+
+	// Err is the reason why the operation failed, nil if it succeeded.
+	Err error
+}
+
+// Runner is implemented by Observers which carry out work in the background, e.g. delivering
+// notifications about observations. Run is invoked in a go-routine tracked by the refresher once
+// it starts, and must return soon after the given context is done, which it is once the refresher
+// is stopped and its other go-routines have exited, such that no more observations are made. The
+// refresher is not done (see Done and StopAndWait) before Run has returned.
+type Runner interface {
+	Run(ctx context.Context)
+}
+
+// WithObserver is the refresher Option to add an Observer. This
+// Option can be provided multiple times to add multiple Observers.
+func WithObserver[T any](observer Observer[T]) Option[T] {
+	return func(r *refresher[T]) { r.observers = append(r.observers, observer) }
+}
+
+// startRunners starts the observers which implement Runner. It must only be called by
+// ensureStarted, with routinesMu read-locked and once it checked that the refresher was not stopped.
+func (r *refresher[T]) startRunners() {
+	for _, observer := range r.observers {
+		if runner, ok := observer.(Runner); ok {
+			r.runners.Add(1)
+			go func() {
+				defer r.runners.Done()
+				runner.Run(r.runnersCtx)
+			}()
+		}
+	}
+}
+
+// observeRefresh notifies all observers of the outcome of a refresh attempt.
+func (r *refresher[T]) observeRefresh(observation RefreshObservation[T]) {
+	if len(r.observers) == 0 {
+		return
+	}
+	observation.Summary, _ = r.redact(observation.Refreshable)
+	for _, observer := range r.observers {
+		r.emit(func() { observer.ObserveRefresh(observation) })
+	}
+}
+
+// observeStorageRead notifies all observers of the outcome of a reading from storage.
+func (r *refresher[T]) observeStorageRead(observation StorageObservation[T]) {
+	if len(r.observers) == 0 {
+		return
+	}
+	observation.Summary, _ = r.redact(observation.Refreshable)
+	for _, observer := range r.observers {
+		r.emit(func() { observer.ObserveStorageRead(observation) })
+	}
+}
+
+// observeStorageWrite notifies all observers of the outcome of a writing to storage.
+func (r *refresher[T]) observeStorageWrite(observation StorageObservation[T]) {
+	if len(r.observers) == 0 {
+		return
+	}
+	observation.Summary, _ = r.redact(observation.Refreshable)
+	for _, observer := range r.observers {
+		r.emit(func() { observer.ObserveStorageWrite(observation) })
+	}
+}
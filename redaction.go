@@ -0,0 +1,83 @@
+package refresh
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// Redactor renders safe summaries of values (e.g. a token's prefix and expiry) for logs,
+// observations, and debug endpoints, which otherwise never include anything about values
+// other than their timestamps.
+type Redactor[T any] interface {
+	// Redact returns a summary of the given Refreshable which is safe to expose.
+	Redact(*Refreshable[T]) string
+}
+
+// RedactorFunc is a function which implements Redactor.
+type RedactorFunc[T any] func(*Refreshable[T]) string
+
+// Redact returns a summary of the given Refreshable by calling the function.
+func (f RedactorFunc[T]) Redact(refreshable *Refreshable[T]) string { return f(refreshable) }
+
+// WithRedactor is the refresher Option to set a Redactor, the summaries of which are included in
+// the refresher's logs (under the "value" attribute), in the RefreshObservations and
+// StorageObservations of its Observers, and in its Status when registered in a Manager (unless
+// WithValueSummary is set). Summaries are also available to other packages through Redact.
+func WithRedactor[T any](redactor Redactor[T]) Option[T] {
+	return func(r *refresher[T]) { r.redactor = redactor }
+}
+
+// PrefixRedactor returns a Redactor which summarizes string values (e.g. opaque tokens) as their
+// first n characters followed by their expiry, e.g. "ghs_... (expires at 2024-01-02T15:04:05Z)".
+// Values not at least four times as long as the prefix are fully redacted, so as not to reveal
+// a significant part of them.
+func PrefixRedactor[T ~string](n int) Redactor[T] {
+	return RedactorFunc[T](func(refreshable *Refreshable[T]) string {
+		value := string(refreshable.Value)
+		prefix := "***"
+		if n > 0 && utf8.RuneCountInString(value) >= 4*n {
+			prefix = string([]rune(value)[:n]) + "..."
+		}
+		return fmt.Sprintf("%s (expires at %s)", prefix, refreshable.ExpiresAt.Format(time.RFC3339))
+	})
+}
+
+// redacting is implemented by Refreshers which may have a Redactor.
+type redacting[T any] interface {
+	redact(*Refreshable[T]) (string, bool)
+}
+
+// Redact returns the summary of the given Refreshable rendered by the Redactor of the given
+// Refresher (see WithRedactor), or false if the Refresher has no Redactor. It allows packages
+// which observe Refreshers (e.g. metrics exporters) to expose the same summaries.
+func Redact[T any](r Refresher[T], refreshable *Refreshable[T]) (string, bool) {
+	redacting, ok := r.(redacting[T])
+	if !ok || refreshable == nil {
+		return "", false
+	}
+	return redacting.redact(refreshable)
+}
+
+// redact returns the summary of the given Refreshable rendered by the refresher's Redactor,
+// or false if there is no Redactor (or it panicked).
+func (r *refresher[T]) redact(refreshable *Refreshable[T]) (string, bool) {
+	if r.redactor == nil || refreshable == nil {
+		return "", false
+	}
+	var summary string
+	if err := r.guard("redactor", func() { summary = r.redactor.Redact(refreshable) }); err != nil {
+		return "", false
+	}
+	return summary, true
+}
+
+// valueAttrs returns the log attributes describing the given Refreshable, which
+// are its timestamps and, if the refresher has a Redactor, its summary.
+func (r *refresher[T]) valueAttrs(refreshable *Refreshable[T]) []any {
+	attrs := []any{"issued_at", refreshable.IssuedAt, "expires_at", refreshable.ExpiresAt}
+	if summary, ok := r.redact(refreshable); ok {
+		attrs = append(attrs, "value", summary)
+	}
+	return attrs
+}
@@ -2,25 +2,137 @@ package refresh
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Refresher represents an entity in charge of maintaining an expiring value "fresh".
+//
+// A Refresher never invokes its RefreshFunc concurrently: at most one refresh is in flight at
+// any time, whether it was scheduled or triggered explicitly (e.g. via GetFresh or ForceRefresh),
+// and concurrent triggers join the refresh in flight, all receiving the same result. RefreshFuncs
+// therefore need not guard against concurrent invocations by the same Refresher.
 type Refresher[T any] interface {
 	// WaitForInitialValue will return as soon as an initial value is loaded onto
 	// the Refresher, or a timeout of the specified duration, whichever happens first.
+	//
+	// It may be called any number of times, from any number of go-routines. Calls made while
+	// the Refresher is initializing all return the outcome of its initialization. Calls made
+	// after initialization failed wait for a value acquired later (e.g. by a retry) instead.
+	//
+	// Errors wrap ErrInitTimeout on timeout, ErrRefreshFailed if the initial refresh failed,
+	// or ErrStopped if the Refresher stopped, alongside any underlying cause.
 	WaitForInitialValue(timeout time.Duration) error
 
-	// GetCurrent returns the current value as a Refreshable.
+	// GetCurrent returns the current value as a Refreshable. Expired values
+	// are handed out (or withheld) as per the Refresher's StalePolicy.
 	GetCurrent() *Refreshable[T]
 
+	// GetCurrentFresh returns the current value as a Refreshable, enforcing its expiry.
+	// If the current value is expired, the returned error wraps ErrStale (or is nil, for
+	// StalePolicyReturnNil). If there is no value yet, the returned error wraps ErrNoValue.
+	GetCurrentFresh() (*Refreshable[T], error)
+
+	// GetValue returns the current value, enforcing its expiry as GetCurrentFresh does. If there
+	// is no value yet, the zero value is returned alongside an error wrapping ErrNoValue. If the
+	// value is expired, the returned error wraps ErrStale (and the value returned is as per the
+	// StalePolicy, or the zero value if withheld).
+	GetValue() (T, error)
+
+	// MustValue returns the current value, as GetCurrent does. It panics if there is no
+	// value (yet), or if an expired value is withheld as per the StalePolicy, which makes it
+	// suitable after a successful call to WaitForInitialValue with StalePolicyReturnStale.
+	MustValue() T
+
+	// GetCurrentUnsafe returns whatever value is currently installed, without applying
+	// the StalePolicy or checking for expiry. It never blocks and never allocates, which
+	// makes it suitable for latency-critical paths. The returned value may be nil (if no
+	// value was loaded yet) or expired, and callers are responsible for checking both.
+	//
+	// The returned Refreshable is shared and must be treated as read-only.
+	GetCurrentUnsafe() *Refreshable[T]
+
+	// GetFresh returns the current value if it is not expired. Otherwise it triggers an
+	// immediate refresh (or joins one already in progress) and blocks until it completes
+	// or the given context is done, whichever happens first.
+	GetFresh(ctx context.Context) (*Refreshable[T], error)
+
+	// ForceRefresh triggers an immediate refresh (or joins one already in progress) and
+	// blocks until it completes or the given context is done, whichever happens first.
+	// Callers joining a refresh in progress receive its result, even if it started before
+	// the call (e.g. a scheduled refresh). Errors wrap ErrRefreshFailed (alongside the error
+	// returned by the RefreshFunc), or ErrStopped if the Refresher stopped.
+	ForceRefresh(ctx context.Context) (*Refreshable[T], error)
+
 	// GetNextRefreshTime returns the time at which the value will be refreshed next.
 	GetNextRefreshTime() time.Time
 
+	// Pause suspends background refreshing until Resume is called. Explicit
+	// refreshes (e.g. via GetFresh) are still carried out while paused.
+	Pause()
+
+	// Resume resumes background refreshing after a call to Pause. If a refresh
+	// became due while paused, it is carried out immediately.
+	Resume()
+
 	// Stop stops the Refresher's go-routines and cleans up associated resources.
+	// It does not wait for them to exit. It is safe to call Stop more than once.
 	Stop()
+
+	// StopAndWait stops the Refresher and waits for its go-routines, including any
+	// in-progress refresh, storage write, and event handlers, to exit, or for the
+	// given context to be done, whichever happens first.
+	StopAndWait(ctx context.Context) error
+
+	// Done returns a channel which is closed once the Refresher has stopped
+	// and all of its go-routines have exited.
+	Done() <-chan struct{}
+
+	// Cause returns the reason why the Refresher stopped, or nil if it has not stopped.
+	// An explicit call to Stop results in ErrStopped, and the parent context of a Refresher
+	// created with NewRefresherContext being done results in that context's cause.
+	Cause() error
+
+	// Stats returns counters about the Refresher's refresh attempts.
+	Stats() Stats
+
+	// GetPrevious returns the value which was current before the current one, or nil if
+	// there was none. It may be expired. This allows for grace windows during rotations,
+	// e.g. accepting signatures by both the previous and the current signing key.
+	GetPrevious() *Refreshable[T]
+
+	// GetHistory returns the last values of the Refresher (as many as set with WithHistory,
+	// by default only the current one) which are not expired, newest first.
+	GetHistory() []*Refreshable[T]
+
+	// Subscribe registers a function which is called with every new value of the Refresher,
+	// whether refreshed or loaded from Storage, until the returned function is called. Like
+	// other event handlers, it is called in the background (in order with the other handlers)
+	// unless WithSynchronousCallbacks is set.
+	Subscribe(fn func(*Refreshable[T])) (unsubscribe func())
+
+	// Events returns a channel on which the Refresher sends an Event for each of its refreshes,
+	// storage operations, and expiries, e.g. for a single consumer feeding an audit log, rather
+	// than setting each event handler. The channel is created on the first call (only later
+	// events are sent on it) and the same one is returned on every call. Events are dropped
+	// rather than block the Refresher when the channel's buffer (see WithEventBufferSize) is
+	// full, except for its last event (EventStopped), after which the channel is closed.
+	Events() <-chan Event[T]
+
+	// SetRefreshFunc replaces the Refresher's RefreshFunc, e.g. to switch issuers during a
+	// dynamic reconfiguration without rebuilding the Refresher. It is safe for concurrent use
+	// and takes effect at the next refresh (any middleware keep wrapping the new function).
+	// The next refresh remains as scheduled unless WithImmediateRefresh is given.
+	SetRefreshFunc(refreshFunc RefreshFunc[T], opts ...SwapOption)
+
+	// SetRefreshStrategy replaces the Refresher's RefreshStrategy. It is safe for concurrent
+	// use and takes effect at the next refresh, i.e. the next refresh remains as scheduled
+	// (by the previous RefreshStrategy) unless WithImmediateRefresh is given.
+	SetRefreshStrategy(refreshStrategy RefreshStrategy[T], opts ...SwapOption)
 }
 
 // Refreshable represents a refreshable value.
@@ -28,12 +140,31 @@ type Refreshable[T any] struct {
 	Value     T
 	IssuedAt  time.Time
 	ExpiresAt time.Time
+
+	// RefreshAt optionally overrides the refresher's RefreshStrategy for this value only,
+	// e.g. when an issuer returns explicit rotation instructions. If set (non-zero), the
+	// value is refreshed at exactly this time (subject to any blackout windows), rather
+	// than at the time determined by the RefreshStrategy.
+	RefreshAt time.Time
+
+	// NotBefore is optionally the earliest time at which the value may be refreshed, e.g.
+	// when an issuer with strict rate limits publishes when new values will be available.
+	// It is a hint for RefreshStrategies (see strategies.NewHinted) and does not constrain
+	// explicit refreshes.
+	NotBefore time.Time
 }
 
 // RefreshFunc returns a new value as well as when it expires. If a non-nil error is returned,
 // both the value and the time will be ignored and their current value will be maintained.
+// A Refresher never invokes its RefreshFunc concurrently.
 type RefreshFunc[T any] func(context.Context) (*Refreshable[T], error)
 
+// RefreshFuncWithPrevious is a RefreshFunc which is also given the previous (current) value,
+// e.g. for refresh token grants and sliding sessions, where the previous value is needed to
+// obtain the next one. The previous value is nil for the initial refresh, unless a value was
+// loaded from Storage, and it may be expired. It is shared and must be treated as read-only.
+type RefreshFuncWithPrevious[T any] func(ctx context.Context, previous *Refreshable[T]) (*Refreshable[T], error)
+
 // Option represents a refresher configuration option.
 type Option[T any] func(*refresher[T])
 
@@ -54,6 +185,18 @@ func WithStorage[T any](storage Storage[T]) Option[T] {
 	return func(r *refresher[T]) { r.storage = storage }
 }
 
+// WithStorageReadRetry is the refresher Option to retry the initial reading of the Refreshable
+// from storage up to the given number of attempts (in total), waiting the given backoff after
+// the first failed attempt and doubling it after every subsequent one, before falling back
+// to acquiring a fresh value. Reads which fail because nothing was stored yet (ErrNotStored)
+// are not retried. Only the last failure is reported to the storage-read-failure event handler.
+func WithStorageReadRetry[T any](attempts int, backoff time.Duration) Option[T] {
+	return func(r *refresher[T]) {
+		r.storageReadAttempts = attempts
+		r.storageReadBackoff = backoff
+	}
+}
+
 // WithOnRefreshSuccess is the refresher Option to set a callback function to be fired
 // after a successful refreshing of the Refreshable.
 func WithOnRefreshSuccess[T any](onRefreshSuccess func(*Refreshable[T], time.Time)) Option[T] {
@@ -92,23 +235,121 @@ func WithOnStorageWriteFailure[T any](onStorageWriteFailure func(error)) Option[
 
 // refresher is the private, default implementation of the Refresher interface.
 type refresher[T any] struct {
-	sync.RWMutex
-
-	// managed with private getters wrapping the mutex
-	current   *Refreshable[T]
-	refreshAt time.Time
+	// managed with private getters wrapping atomic loads such that reads never block
+	current   atomic.Pointer[Refreshable[T]]
+	refreshAt atomic.Pointer[time.Time]
 
 	// managed by Stop()
-	refreshCtxCancel context.CancelFunc
+	refreshCtx       context.Context
+	refreshCtxCancel context.CancelCauseFunc
+
+	// managed by spawn() and drain()
+	routines   sync.WaitGroup
+	routinesMu sync.RWMutex
+	done       chan struct{}
+
+	// managed by startRunners() and drain(), the go-routines of observers which implement Runner
+	runners       sync.WaitGroup
+	runnersCtx    context.Context
+	runnersCancel context.CancelFunc
 
-	// managed by start()
-	initializationResult chan error
+	// managed by refreshShared()
+	flightMu sync.Mutex
+	flight   *flight[T]
 
-	refreshFunc     RefreshFunc[T]
+	// signals the start() routine that the value was refreshed out of schedule
+	rescheduled chan struct{}
+
+	// signals the start() routine to refresh as soon as possible, see trigger()
+	triggered chan struct{}
+
+	// managed by Pause() and Resume()
+	paused  atomic.Bool
+	resumed chan struct{}
+
+	// managed by start(), closed once initialized (with initErr set beforehand)
+	initialized chan struct{}
+	initErr     error
+
+	// managed by initialize(), closed once the initial storage read (if any) is done
+	storageRead     chan struct{}
+	storageReadOnce sync.Once
+
+	// managed by updateValue(), closed once the refresher has a value
+	hasValue     chan struct{}
+	hasValueOnce sync.Once
+
+	refreshFunc     RefreshFuncWithPrevious[T]
 	refreshStrategy RefreshStrategy[T]
-	retryDelay      time.Duration
 
-	storage Storage[T]
+	// managed by SetRefreshFunc() and SetRefreshStrategy()
+	swappableRefreshFunc atomic.Pointer[RefreshFuncWithPrevious[T]]
+	swappableStrategy    atomic.Pointer[RefreshStrategy[T]]
+
+	// managed by acquire()
+	renewFunc     RenewFunc[T]
+	renewDecision RenewDecisionFunc[T]
+	maxRenewals   int
+	renewals      int
+
+	retryDelay  time.Duration
+	stalePolicy StalePolicy
+
+	storage             Storage[T]
+	storageReadAttempts int
+	storageReadBackoff  time.Duration
+	minStorageFreshness time.Duration
+
+	initialRefreshAttempts int
+	initialRefreshBackoff  time.Duration
+
+	blackoutWindows []TimeWindow
+
+	observers []Observer[T]
+	sinks     []Sink[T]
+
+	logger *slog.Logger
+	name   string
+
+	validators      []ValidateFunc[T]
+	refreshContexts []func(context.Context) context.Context
+	middleware      []RefreshMiddleware[T]
+
+	synchronousCallbacks bool
+	storeBeforeSwap      bool
+	previous             atomic.Pointer[Refreshable[T]]
+
+	// managed by emit() and dispatch()
+	dispatchMu    sync.Mutex
+	dispatchQueue []func()
+	dispatching   bool
+
+	historyMu   sync.Mutex
+	history     []*Refreshable[T]
+	historySize int
+
+	lazyStart bool
+	startOnce sync.Once
+	started   atomic.Bool
+
+	statsMu sync.Mutex
+	stats   Stats
+
+	// managed by storeMetadata() and restoreMetadata(), the state of the last stored Metadata
+	metadataMu          sync.Mutex
+	storedFailures      int64
+	storedNextRefreshAt time.Time
+
+	subscribersMu    sync.Mutex
+	subscribers      map[uint64]func(*Refreshable[T])
+	nextSubscriberID uint64
+
+	maxConsecutiveFailures int
+
+	minRefreshInterval time.Duration
+	maxRefreshInterval time.Duration
+
+	initializationDeadline time.Duration
 
 	// event handlers
 	onRefreshSuccess      func(*Refreshable[T], time.Time)
@@ -117,20 +358,73 @@ type refresher[T any] struct {
 	onRefreshFailure      func(error)
 	onStorageReadFailure  func(error)
 	onStorageWriteFailure func(error)
+	onRefreshDeferred     func(time.Time, time.Time)
+	onChange              func(*Refreshable[T])
+	onChangeEqual         func(T, T) bool
+	onExhausted           func(error)
+	onExpire              func(*Refreshable[T])
+	onStorageHit          func(*Refreshable[T], StorageHit)
+	onPanic               func(*PanicError)
+
+	nilRefreshablePolicy NilRefreshablePolicy[T]
+
+	redactor Redactor[T]
+
+	fallbacks []RefreshFunc[T]
+
+	// managed by Events() and publishStopped()
+	eventsMu        sync.Mutex
+	events          atomic.Pointer[chan Event[T]]
+	eventsStopped   bool
+	eventBufferSize int
 }
 
 // NewRefresher returns a Refresher initialized with the given RefreshFunc and Option(s).
 // The recommended usage is to call WaitForInitialValue(<timeout>) immediately afterwards.
 func NewRefresher[T any](refreshFunc RefreshFunc[T], opts ...Option[T]) Refresher[T] {
+	return NewRefresherWithPrevious(func(ctx context.Context, _ *Refreshable[T]) (*Refreshable[T], error) {
+		return refreshFunc(ctx)
+	}, opts...)
+}
+
+// NewRefresherWithPrevious returns a Refresher initialized with the given RefreshFuncWithPrevious
+// and Option(s). The recommended usage is to call WaitForInitialValue(<timeout>) immediately afterwards.
+func NewRefresherWithPrevious[T any](refreshFunc RefreshFuncWithPrevious[T], opts ...Option[T]) Refresher[T] {
+	return NewRefresherWithPreviousContext(context.Background(), refreshFunc, opts...)
+}
+
+// NewRefresherContext is like NewRefresher, but the Refresher's lifetime is bound to the given
+// context: the Refresher stops as soon as the context is done, with the context's cause as its
+// Cause, e.g. such that refreshers stop along with the errgroup of the service running them.
+// Refresh attempts are carried out with contexts derived from the given context, so its
+// values are visible to the RefreshFunc.
+func NewRefresherContext[T any](ctx context.Context, refreshFunc RefreshFunc[T], opts ...Option[T]) Refresher[T] {
+	return NewRefresherWithPreviousContext(ctx, func(ctx context.Context, _ *Refreshable[T]) (*Refreshable[T], error) {
+		return refreshFunc(ctx)
+	}, opts...)
+}
+
+// NewRefresherWithPreviousContext is like NewRefresherWithPrevious, but the Refresher's
+// lifetime is bound to the given context (see NewRefresherContext).
+func NewRefresherWithPreviousContext[T any](ctx context.Context, refreshFunc RefreshFuncWithPrevious[T], opts ...Option[T]) Refresher[T] {
 	ref := &refresher[T]{
-		refreshFunc:          refreshFunc,
-		current:              nil,
-		refreshAt:            time.Now(),
-		initializationResult: make(chan error),
+		initialized: make(chan struct{}),
+		storageRead: make(chan struct{}),
+		hasValue:    make(chan struct{}),
+		done:        make(chan struct{}),
+		rescheduled: make(chan struct{}, 1),
+		triggered:   make(chan struct{}, 1),
+		resumed:     make(chan struct{}, 1),
 
 		// default option values
 		retryDelay:      time.Minute * 15,
-		refreshStrategy: RefreshStrategyFromFunction(defaultRefreshStrategyFunc[T]),
+		refreshStrategy: DefaultRefreshStrategy[T](),
+		stalePolicy:     StalePolicyReturnStale,
+
+		storageReadAttempts:    1,
+		initialRefreshAttempts: 1,
+		historySize:            1,
+		eventBufferSize:        DefaultEventBufferSize,
 
 		// event handlers
 		onRefreshSuccess:      func(r *Refreshable[T], refreshAt time.Time) { /* NOOP */ },
@@ -139,15 +433,29 @@ func NewRefresher[T any](refreshFunc RefreshFunc[T], opts ...Option[T]) Refreshe
 		onRefreshFailure:      func(err error) { /* NOOP */ },
 		onStorageReadFailure:  func(err error) { /* NOOP */ },
 		onStorageWriteFailure: func(err error) { /* NOOP */ },
+		onRefreshDeferred:     func(scheduledAt, deferredTo time.Time) { /* NOOP */ },
+		onStorageHit:          func(r *Refreshable[T], hit StorageHit) { /* NOOP */ },
+		onPanic:               func(err *PanicError) { /* NOOP */ },
+
+		nilRefreshablePolicy: NilRefreshableIsError[T],
 	}
 	for _, opt := range opts {
 		opt(ref)
 	}
+	ref.swappableRefreshFunc.Store(&refreshFunc)
+	ref.swappableStrategy.Store(&ref.refreshStrategy)
+	ref.refreshFunc = ref.withMiddleware(ref.callRefreshFunc)
 
-	refreshCtx, refreshCtxCancel := context.WithCancel(context.Background())
-	ref.refreshCtxCancel = refreshCtxCancel
+	now := time.Now()
+	ref.refreshAt.Store(&now)
+
+	ref.refreshCtx, ref.refreshCtxCancel = context.WithCancelCause(ctx)
+	ref.runnersCtx, ref.runnersCancel = context.WithCancel(context.WithoutCancel(ctx))
 
-	go ref.start(refreshCtx)
+	if !ref.lazyStart {
+		ref.ensureStarted()
+	}
+	go ref.drain()
 
 	return ref
 }
@@ -155,128 +463,479 @@ func NewRefresher[T any](refreshFunc RefreshFunc[T], opts ...Option[T]) Refreshe
 // WaitForInitialValue will return as soon as an initial value is loaded onto
 // the refresher, or a timeout of the specified duration, whichever happens first.
 func (r *refresher[T]) WaitForInitialValue(timeout time.Duration) error {
-	if r.GetCurrent() != nil {
+	r.ensureStarted()
+	if r.getCurrent() != nil {
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-r.initialized:
+		// initialization already failed, wait for a value acquired later
+		select {
+		case <-timer.C:
+			return r.wrapErr(ErrInitTimeout, r.initErr)
+		case <-r.hasValue:
+			return nil
+		case <-r.done:
+			return r.wrapErr(ErrStopped, r.Cause())
+		}
+	default:
+	}
+
+	select {
+	case <-timer.C:
+		return r.wrapErr(ErrInitTimeout, nil)
+	case <-r.hasValue:
+		return nil
+	case <-r.initialized:
+		if r.getCurrent() == nil {
+			return r.initErr
+		}
 		return nil
 	}
+}
 
+// waitForInitialization waits for the refresher to have a value, returning its initialization
+// error if it has none once initialized, or the context's error if it is done first.
+func (r *refresher[T]) waitForInitialization(ctx context.Context) error {
+	r.ensureStarted()
 	select {
-	case <-time.After(timeout):
-		return fmt.Errorf("timed out after %s waiting for initial value", timeout)
-	case err := <-r.initializationResult:
-		if err != nil {
-			return fmt.Errorf("failed to acquire initial value: %v", err)
+	case <-r.hasValue:
+		return nil
+	case <-r.initialized:
+		if r.getCurrent() == nil {
+			return r.initErr
 		}
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-// GetCurrent returns the current value.
+// GetCurrent returns the current value, as per the refresher's StalePolicy.
 func (r *refresher[T]) GetCurrent() *Refreshable[T] {
-	r.RLock()
-	defer r.RUnlock()
-	return r.current
+	r.ensureStarted()
+	current, _ := r.applyStalePolicy(r.getCurrent())
+	return current
+}
+
+// GetCurrentFresh returns the current value, enforcing its expiry.
+func (r *refresher[T]) GetCurrentFresh() (*Refreshable[T], error) {
+	r.ensureStarted()
+	return r.applyStalePolicy(r.getCurrent())
+}
+
+// GetCurrentUnsafe returns the installed value as-is, with a single atomic load.
+func (r *refresher[T]) GetCurrentUnsafe() *Refreshable[T] {
+	return r.current.Load()
+}
+
+// GetFresh returns the current value if it is not expired, otherwise it
+// waits for an immediate (possibly shared) refresh to complete.
+func (r *refresher[T]) GetFresh(ctx context.Context) (*Refreshable[T], error) {
+	r.ensureStarted()
+	if err := r.awaitStorageRead(ctx); err != nil {
+		return nil, err
+	}
+	if current := r.getCurrent(); current != nil && !isExpired(current, time.Now()) {
+		return current, nil
+	}
+	return r.refreshShared().wait(ctx)
+}
+
+// ForceRefresh waits for an immediate (possibly shared) refresh to complete.
+func (r *refresher[T]) ForceRefresh(ctx context.Context) (*Refreshable[T], error) {
+	r.ensureStarted()
+	return r.refreshShared().wait(ctx)
+}
+
+// awaitStorageRead waits for the initial storage read (if any) to be done, such that
+// a fresh value read from storage is used rather than immediately refreshed, or for
+// the refresher to be stopped, returning an error if the given context is done first.
+func (r *refresher[T]) awaitStorageRead(ctx context.Context) error {
+	select {
+	case <-r.storageRead:
+		return nil
+	case <-r.refreshCtx.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// storageReadDone signals that the initial storage read (if any) is done.
+func (r *refresher[T]) storageReadDone() {
+	r.storageReadOnce.Do(func() { close(r.storageRead) })
+}
+
+// getCurrent returns the current value regardless of its expiry.
+func (r *refresher[T]) getCurrent() *Refreshable[T] {
+	return r.current.Load()
+}
+
+// Pause suspends background refreshing until Resume is called.
+func (r *refresher[T]) Pause() {
+	if !r.paused.Swap(true) {
+		r.log(slog.LevelInfo, "refresher paused")
+	}
+}
+
+// Resume resumes background refreshing after a call to Pause.
+func (r *refresher[T]) Resume() {
+	if r.paused.Swap(false) {
+		r.log(slog.LevelInfo, "refresher resumed")
+		select {
+		case r.resumed <- struct{}{}:
+		default:
+		}
+	}
 }
 
 // Stop stops the refresher's go-routines and cleans up associated resources.
 func (r *refresher[T]) Stop() {
-	r.refreshCtxCancel()
+	r.refreshCtxCancel(ErrStopped)
+}
+
+// StopAndWait stops the refresher and waits for its go-routines to exit.
+func (r *refresher[T]) StopAndWait(ctx context.Context) error {
+	r.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.done:
+		return nil
+	}
+}
+
+// Done returns a channel which is closed once the refresher has
+// stopped and all of its go-routines have exited.
+func (r *refresher[T]) Done() <-chan struct{} {
+	return r.done
+}
+
+// spawn runs the given function in a new go-routine tracked by the refresher, such
+// that the refresher is not considered done until the function returns.
+//
+// Only the refresher's own (tracked) go-routines may call spawn directly. Others must
+// hold routinesMu (read-locked) and check that the refresher was not stopped first.
+func (r *refresher[T]) spawn(fn func()) {
+	r.routines.Add(1)
+	go func() {
+		defer r.routines.Done()
+		fn()
+	}()
+}
+
+// drain waits for the refresher to be stopped and for all of its tracked go-routines
+// to exit, after which it closes the refresher's done channel.
+func (r *refresher[T]) drain() {
+	<-r.refreshCtx.Done()
+
+	// wait for untracked go-routines which may be about to spawn tracked ones
+	r.routinesMu.Lock()
+	r.routinesMu.Unlock()
+
+	r.routines.Wait()
+
+	// runners are stopped last, such that they observe everything the refresher did
+	r.runnersCancel()
+	r.runners.Wait()
+
+	r.publishStopped()
+	close(r.done)
+
+	r.log(slog.LevelInfo, "refresher stopped", "cause", context.Cause(r.refreshCtx))
+}
+
+// Cause returns the reason why the refresher stopped, or nil if it has not stopped.
+func (r *refresher[T]) Cause() error {
+	return context.Cause(r.refreshCtx)
 }
 
 // GetNextRefreshTime returns the time at which the value will be refreshed next.
 func (r *refresher[T]) GetNextRefreshTime() time.Time {
-	r.RLock()
-	defer r.RUnlock()
-	return r.refreshAt
+	return *r.refreshAt.Load()
 }
 
 // updateValue sets the current value of the Refreshable along with the refreshAt time.
+//
+// The value and the refreshAt time are stored independently, so a concurrent reader
+// may briefly observe the new value alongside the previous refreshAt time (or vice
+// versa). Updates are serialized: refreshes (see refreshShared) do not begin before
+// the initial storage read is done, and at most one is in progress at any time.
 func (r *refresher[T]) updateValue(newValue *Refreshable[T], refreshAt time.Time) {
-	r.Lock()
-	defer r.Unlock()
-	r.current = newValue
-	r.refreshAt = refreshAt
+	oldValue := r.current.Swap(newValue)
+	if oldValue != nil {
+		r.previous.Store(oldValue)
+	}
+	r.recordHistory(newValue)
+	r.hasValueOnce.Do(func() { close(r.hasValue) })
+	r.refreshAt.Store(&refreshAt)
+	r.notifyChange(oldValue, newValue)
+	r.notifySubscribers(newValue)
+	r.writeSinks(newValue)
+}
+
+// getRefreshAt returns the time at which the given Refreshable should be refreshed.
+// If the refresher's RefreshStrategy panics, the default RefreshStrategy is used instead.
+func (r *refresher[T]) getRefreshAt(refreshable *Refreshable[T]) time.Time {
+	if !refreshable.RefreshAt.IsZero() {
+		return refreshable.RefreshAt
+	}
+	var refreshAt time.Time
+	if err := r.guard("refresh strategy", func() { refreshAt = r.getRefreshStrategy().GetRefreshAt(refreshable) }); err != nil {
+		return DefaultRefreshStrategy[T]().GetRefreshAt(refreshable)
+	}
+	return refreshAt
+}
+
+// flight represents a refresh in progress, the result of which is shared by all its callers.
+type flight[T any] struct {
+	done  chan struct{}
+	value *Refreshable[T]
+	err   error
+}
+
+// wait blocks until the flight lands or the given context is done, whichever happens first.
+func (f *flight[T]) wait(ctx context.Context) (*Refreshable[T], error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-f.done:
+		return f.value, f.err
+	}
+}
+
+// refreshShared starts a refresh in the background, unless one is already in progress,
+// and returns the in-progress refresh such that all concurrent callers share its result.
+// It is the only way in which refreshes are started, which is what guarantees that the
+// refresher's RefreshFunc (and RenewFunc) are never invoked concurrently.
+func (r *refresher[T]) refreshShared() *flight[T] {
+	r.flightMu.Lock()
+	defer r.flightMu.Unlock()
+
+	if r.flight != nil {
+		return r.flight
+	}
+
+	f := &flight[T]{done: make(chan struct{})}
+
+	r.routinesMu.RLock()
+	defer r.routinesMu.RUnlock()
+
+	if r.refreshCtx.Err() != nil {
+		f.err = r.wrapErr(ErrStopped, context.Cause(r.refreshCtx))
+		close(f.done)
+		return f
+	}
+
+	r.flight = f
+	r.spawn(func() {
+		// a value read from storage must not be installed over a refreshed one
+		select {
+		case <-r.storageRead:
+		case <-r.refreshCtx.Done():
+		}
+		f.value, f.err = r.refresh(r.refreshCtx)
+
+		r.flightMu.Lock()
+		r.flight = nil
+		r.flightMu.Unlock()
+
+		close(f.done)
+	})
+
+	return f
 }
 
 // refresh invokes the refresher's refreshFunc and updates its internal values.
-func (r *refresher[T]) refresh(ctx context.Context) error {
-	newValue, err := r.refreshFunc(ctx)
+// It must only ever be called by refreshShared().
+func (r *refresher[T]) refresh(ctx context.Context) (*Refreshable[T], error) {
+	began := time.Now()
+	var newValue *Refreshable[T]
+	var source int
+	var err error
+	if panicErr := r.guard("refresh function", func() { newValue, source, err = r.acquire(ctx) }); panicErr != nil {
+		newValue, err = nil, panicErr
+	}
+	if feedback, ok := r.getRefreshStrategy().(FeedbackStrategy[T]); ok {
+		_ = r.guard("refresh strategy", func() { feedback.RecordRefresh(time.Since(began), err) })
+	}
+	if err == nil && r.storeBeforeSwap {
+		err = r.store(context.WithoutCancel(ctx), newValue)
+	}
 	if err != nil {
-		go r.onRefreshFailure(err)
-		return err
+		err = r.wrapErr(ErrRefreshFailed, err)
+		r.recordRefresh(err)
+		if r.logs(slog.LevelWarn) {
+			r.log(slog.LevelWarn, "refresh failed", "error", err, "duration", time.Since(began))
+		}
+		r.emit(func() { r.onRefreshFailure(err) })
+		r.observeRefresh(RefreshObservation[T]{Duration: time.Since(began), Err: err})
+		r.publish(Event[T]{Kind: EventRefreshFailed, Duration: time.Since(began), Err: err})
+		if r.storage != nil {
+			retryAt := r.deferredPastBlackouts(r.getCurrent(), time.Now().Add(r.retryDelayFor(err)))
+			r.emit(func() { r.storeMetadata(context.WithoutCancel(ctx), retryAt) })
+		}
+		return nil, err
+	}
+	r.recordRefresh(nil)
+	nextRefreshAt := r.deferForBlackouts(newValue, r.clampRefreshAt(r.getRefreshAt(newValue), time.Now()))
+	if r.logs(slog.LevelInfo) {
+		r.log(slog.LevelInfo, "refreshed value",
+			append(r.valueAttrs(newValue), "next_refresh_at", nextRefreshAt, "source", source, "duration", time.Since(began))...)
 	}
-	nextRefreshAt := r.refreshStrategy.GetRefreshAt(newValue)
-	go r.onRefreshSuccess(newValue, nextRefreshAt)
+	r.emit(func() { r.onRefreshSuccess(newValue, nextRefreshAt) })
+	r.observeRefresh(RefreshObservation[T]{Refreshable: newValue, RefreshAt: nextRefreshAt, Duration: time.Since(began)})
+	r.publish(Event[T]{Kind: EventRefreshSucceeded, Refreshable: newValue, RefreshAt: nextRefreshAt, Source: source, Duration: time.Since(began)})
 	r.updateValue(newValue, nextRefreshAt)
-	return nil
+	if r.storage != nil {
+		if !r.storeBeforeSwap {
+			r.emit(func() { _ = r.store(context.WithoutCancel(ctx), newValue) })
+		}
+		r.emit(func() { r.storeMetadata(context.WithoutCancel(ctx), nextRefreshAt) })
+	}
+
+	// let the start() routine know that the refresh schedule changed
+	select {
+	case r.rescheduled <- struct{}{}:
+	default:
+	}
+
+	return newValue, nil
 }
 
-// store attempts to store the current value in Storage.
-func (r *refresher[T]) store(ctx context.Context, refreshable *Refreshable[T]) {
+// store attempts to store a (new) value in Storage.
+func (r *refresher[T]) store(ctx context.Context, refreshable *Refreshable[T]) error {
 	if r.storage == nil {
-		return
+		return nil
 	}
 
+	began := time.Now()
 	if err := r.storage.Put(ctx, refreshable); err != nil {
-		go r.onStorageWriteFailure(err)
-		return
+		r.log(slog.LevelWarn, "storage write failed", "error", err)
+		r.emit(func() { r.onStorageWriteFailure(err) })
+		r.observeStorageWrite(StorageObservation[T]{Duration: time.Since(began), Err: err})
+		r.publish(Event[T]{Kind: EventStorageWrite, Duration: time.Since(began), Err: err})
+		return fmt.Errorf("failed to store value: %v", err)
 	}
-	go r.onStorageWriteSuccess(refreshable)
+	r.log(slog.LevelDebug, "stored value", "expires_at", refreshable.ExpiresAt)
+	r.emit(func() { r.onStorageWriteSuccess(refreshable) })
+	r.observeStorageWrite(StorageObservation[T]{Refreshable: refreshable, Duration: time.Since(began)})
+	r.publish(Event[T]{Kind: EventStorageWrite, Refreshable: refreshable, Duration: time.Since(began)})
+	return nil
 }
 
-// start is a long-lived routine which takes care of periodically
-// invoking the refresher's refresh() method and handling its results.
-//
-// It also signals the initializationResult channel as soon as
-// an initial value is retrieved and available.
-func (r *refresher[T]) start(ctx context.Context) {
-
-	// try retrieve from storage first
-	if r.storage != nil {
+// load attempts to retrieve a value from Storage, retrying
+// failed attempts as per the refresher's storage read retry policy.
+func (r *refresher[T]) load(ctx context.Context) (*Refreshable[T], error) {
+	backoff := r.storageReadBackoff
+	for attempt := 1; ; attempt++ {
 		valueFromStorage, err := r.storage.Get(ctx)
-		if err != nil {
-			go r.onStorageReadFailure(err)
-		} else {
-			refreshAt := r.refreshStrategy.GetRefreshAt(valueFromStorage)
-
-			// if the value is still fresh, we use it
-			if time.Now().Before(refreshAt) {
-				go r.onStorageReadSuccess(valueFromStorage, refreshAt)
-				r.updateValue(valueFromStorage, refreshAt)
-				r.initializationResult <- nil
-			} else {
-				go r.onStorageReadSuccess(valueFromStorage, time.Now())
-			}
+		if err == nil && valueFromStorage == nil {
+			err = errors.New("storage returned neither a value nor an error")
 		}
-	}
-
-	// if the refresher has no value at this point, we need a fresh one.
-	if r.GetCurrent() == nil {
-		if err := r.refresh(ctx); err != nil {
-			r.initializationResult <- err
-		} else {
-			r.initializationResult <- nil
-			go r.store(ctx, r.GetCurrent())
+		if err == nil {
+			return valueFromStorage, nil
 		}
+		if attempt >= r.storageReadAttempts || errors.Is(err, ErrNotStored) {
+			return nil, err
+		}
+		r.log(slog.LevelWarn, "storage read failed, retrying", "error", err, "attempt", attempt, "retry_in", backoff)
+		if !sleep(ctx, backoff) {
+			return nil, err
+		}
+		backoff *= 2
 	}
+}
 
-	close(r.initializationResult) // channel is useless at this point
+// start is a long-lived routine which takes care of periodically
+// invoking the refresher's refresh() method and handling its results.
+//
+// It also signals the initialized channel as soon as an initial
+// value is retrieved and available (or could not be).
+func (r *refresher[T]) start(ctx context.Context) {
+	r.initErr = r.initialize(ctx)
+	close(r.initialized)
 
 	refreshTimer := time.NewTimer(time.Until(r.GetNextRefreshTime()))
 	defer refreshTimer.Stop()
 
+	expiryTimer := r.newExpiryTimer()
+	if expiryTimer != nil {
+		defer expiryTimer.Stop()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return // stop
-		case <-refreshTimer.C:
-			if err := r.refresh(ctx); err != nil {
-				refreshTimer.Reset(r.retryDelay)
-				continue
+		case <-r.rescheduled:
+			resetTimer(refreshTimer, time.Until(r.GetNextRefreshTime()))
+			r.resetExpiryTimer(expiryTimer)
+		case <-expiryTimerC(expiryTimer):
+			if !r.expire() {
+				r.resetExpiryTimer(expiryTimer)
 			}
-			refreshTimer.Reset(time.Until(r.GetNextRefreshTime()))
-			go r.store(ctx, r.GetCurrent())
+		case <-r.resumed:
+			resetTimer(refreshTimer, time.Until(r.GetNextRefreshTime()))
+		case <-r.triggered:
+			r.refreshDue(ctx, refreshTimer)
+		case <-refreshTimer.C:
+			r.refreshDue(ctx, refreshTimer)
+		}
+	}
+}
+
+// refreshDue carries out a refresh which is due (unless the refresher is paused or
+// exhausted), and resets the given refresh timer as per its outcome.
+func (r *refresher[T]) refreshDue(ctx context.Context, refreshTimer *time.Timer) {
+	if r.paused.Load() {
+		r.log(slog.LevelInfo, "refresh due while paused, postponing until resumed")
+		return // refresh (or retry) is carried out when resumed
+	}
+	if r.isExhausted() {
+		return // refresh is carried out when an explicit one succeeds
+	}
+	if _, err := r.refreshShared().wait(ctx); err != nil {
+		if r.isExhausted() {
+			return
+		}
+		retryDelay := time.Until(r.deferForBlackouts(r.getCurrent(), time.Now().Add(r.retryDelayFor(err))))
+		r.log(slog.LevelInfo, "retrying refresh after failure", "retry_in", retryDelay)
+		resetTimer(refreshTimer, retryDelay)
+		return
+	}
+	resetTimer(refreshTimer, time.Until(r.GetNextRefreshTime()))
+}
+
+// sleep waits for the given duration or until the given context is done, whichever happens
+// first, returning false in the latter case. Unlike with time.After, the underlying timer is
+// released as soon as sleep returns rather than when it fires.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// resetTimer stops, drains, and resets a timer such that
+// no stale expiration is delivered after the reset.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
 		}
 	}
+	t.Reset(d)
 }
 
 func defaultRefreshStrategyFunc[T any](refreshable *Refreshable[T]) time.Time {
@@ -0,0 +1,315 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counter returns a RefreshFunc which returns increasing integers valid for the given lifetime.
+func counter(lifetime time.Duration) (RefreshFunc[int], *atomic.Int64) {
+	var n atomic.Int64
+	return func(context.Context) (*Refreshable[int], error) {
+		now := time.Now()
+		return &Refreshable[int]{Value: int(n.Add(1)), IssuedAt: now, ExpiresAt: now.Add(lifetime)}, nil
+	}, &n
+}
+
+func TestStorageReadDoesNotOverwriteExplicitRefresh(t *testing.T) {
+	reading := make(chan struct{})
+	release := make(chan struct{})
+	stored := StorageFromFunctions(
+		func(context.Context) (*Refreshable[int], error) {
+			close(reading)
+			<-release
+			now := time.Now()
+			return &Refreshable[int]{Value: 100, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+		},
+		func(context.Context, *Refreshable[int]) error { return nil },
+	)
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc, WithStorage(stored))
+	defer r.Stop()
+
+	<-reading
+	forced := make(chan *Refreshable[int])
+	go func() {
+		value, err := r.ForceRefresh(context.Background())
+		if err != nil {
+			t.Errorf("failed to force refresh: %v", err)
+		}
+		forced <- value
+	}()
+	time.Sleep(10 * time.Millisecond) // let the forced refresh begin waiting
+	close(release)
+
+	value := <-forced
+	if value == nil || value.Value != 1 {
+		t.Fatalf("forced refresh returned %v, expected a refreshed value", value)
+	}
+	if current := r.GetCurrentUnsafe(); current.Value != 1 {
+		t.Errorf("current value is %d, expected the refreshed value", current.Value)
+	}
+}
+
+func TestGetFreshUsesValueFromStorage(t *testing.T) {
+	release := make(chan struct{})
+	stored := StorageFromFunctions(
+		func(context.Context) (*Refreshable[int], error) {
+			<-release
+			now := time.Now()
+			return &Refreshable[int]{Value: 100, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+		},
+		func(context.Context, *Refreshable[int]) error { return nil },
+	)
+	refreshFunc, calls := counter(time.Hour)
+	r := NewRefresher(refreshFunc, WithStorage(stored))
+	defer r.Stop()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	value, err := r.GetFresh(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get fresh value: %v", err)
+	}
+	if value.Value != 100 {
+		t.Errorf("got %d, expected the value from storage", value.Value)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("refresh function called %d times, expected none", n)
+	}
+}
+
+func TestStorageReadRetriesOnlyTransientErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		err      error
+		expected int64
+	}{
+		{name: "not stored", err: ErrNotStored, expected: 1},
+		{name: "transient", err: errors.New("connection refused"), expected: 3},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var reads atomic.Int64
+			stored := StorageFromFunctions(
+				func(context.Context) (*Refreshable[int], error) {
+					reads.Add(1)
+					return nil, test.err
+				},
+				func(context.Context, *Refreshable[int]) error { return nil },
+			)
+			refreshFunc, _ := counter(time.Hour)
+			r := NewRefresher(refreshFunc, WithStorage(stored), WithStorageReadRetry[int](3, time.Millisecond))
+			defer r.Stop()
+
+			if err := r.WaitForInitialValue(time.Second); err != nil {
+				t.Fatal(err)
+			}
+			if n := reads.Load(); n != test.expected {
+				t.Errorf("storage read %d times, expected %d", n, test.expected)
+			}
+		})
+	}
+}
+
+func TestGetCurrentUnsafeConcurrentWithRefreshes(t *testing.T) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc)
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := 0
+			for j := 0; j < 1000; j++ {
+				current := r.GetCurrentUnsafe()
+				if current.Value < last {
+					t.Errorf("value went back from %d to %d", last, current.Value)
+					return
+				}
+				last = current.Value
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		if _, err := r.ForceRefresh(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestGetCurrentUnsafeDoesNotAllocate(t *testing.T) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc)
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { _ = r.GetCurrentUnsafe() }); allocs != 0 {
+		t.Errorf("GetCurrentUnsafe allocated %v times, expected none", allocs)
+	}
+}
+
+func TestGetCurrentUnsafeDoesNotStartLazyRefresher(t *testing.T) {
+	refreshFunc, calls := counter(time.Hour)
+	r := NewRefresher(refreshFunc, WithLazyStart[int]())
+	defer r.Stop()
+
+	if current := r.GetCurrentUnsafe(); current != nil {
+		t.Errorf("got %v before starting, expected nil", current)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := calls.Load(); n != 0 {
+		t.Errorf("refresh function called %d times, expected none", n)
+	}
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Errorf("refresher did not start: %v", err)
+	}
+}
+
+func BenchmarkGetCurrent(b *testing.B) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc)
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		b.Fatal(err)
+	}
+
+	// refresh concurrently with the readers, such that the value they load keeps changing
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		for ctx.Err() == nil {
+			_, _ = r.ForceRefresh(ctx)
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if r.GetCurrent() == nil {
+				b.Error("no current value")
+				return
+			}
+		}
+	})
+	b.StopTimer()
+	cancel()
+	<-refreshed
+}
+
+// BenchmarkGetCurrentRWMutexBaseline is BenchmarkGetCurrent with the current value guarded by a
+// sync.RWMutex rather than loaded atomically, as a baseline for the latter.
+func BenchmarkGetCurrentRWMutexBaseline(b *testing.B) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc)
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		b.Fatal(err)
+	}
+
+	var mu sync.RWMutex
+	current := r.GetCurrent()
+	getCurrent := func() *Refreshable[int] {
+		mu.RLock()
+		defer mu.RUnlock()
+		return current
+	}
+
+	// refresh concurrently with the readers (at the same rate as in BenchmarkGetCurrent)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		for ctx.Err() == nil {
+			if refreshable, err := r.ForceRefresh(ctx); err == nil {
+				mu.Lock()
+				current = refreshable
+				mu.Unlock()
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if getCurrent() == nil {
+				b.Error("no current value")
+				return
+			}
+		}
+	})
+	b.StopTimer()
+	cancel()
+	<-refreshed
+}
+
+func BenchmarkGetCurrentContended(b *testing.B) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc)
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.SetParallelism(16) // many more readers than processors
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if r.GetCurrent() == nil {
+				b.Error("no current value")
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkRefresh(b *testing.B) {
+	for name, opts := range map[string][]Option[int]{
+		"no handlers": nil,
+		"async handlers": {
+			WithOnRefreshSuccess[int](func(*Refreshable[int], time.Time) {}),
+			WithOnRefreshFailure[int](func(error) {}),
+		},
+		"sync handlers": {
+			WithSynchronousCallbacks[int](),
+			WithOnRefreshSuccess[int](func(*Refreshable[int], time.Time) {}),
+			WithOnRefreshFailure[int](func(error) {}),
+		},
+	} {
+		b.Run(name, func(b *testing.B) {
+			refreshFunc, _ := counter(time.Hour)
+			r := NewRefresher(refreshFunc, opts...)
+			defer r.Stop()
+			if err := r.WaitForInitialValue(time.Second); err != nil {
+				b.Fatal(err)
+			}
+
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := r.ForceRefresh(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
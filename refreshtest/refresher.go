@@ -0,0 +1,423 @@
+// Package refreshtest provides utilities for testing code which consumes refresh.Refresher(s).
+package refreshtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// Refresher is a refresh.Refresher whose value, next refresh time, and failure behavior are
+// set programmatically. It never refreshes on its own; refresh cycles are simulated with Tick.
+//
+// The zero value is not usable, use NewRefresher instead.
+type Refresher[T any] struct {
+	mu sync.Mutex
+
+	current       *refresh.Refreshable[T]
+	previous      *refresh.Refreshable[T]
+	nextRefreshAt time.Time
+	refreshFunc   refresh.RefreshFunc[T]
+	strategy      refresh.RefreshStrategy[T]
+	refreshErr    error
+	refreshes     int
+	stats         refresh.Stats
+	paused        bool
+
+	subscribers      map[uint64]func(*refresh.Refreshable[T])
+	nextSubscriberID uint64
+
+	// closed and replaced whenever the current value or refresh error change
+	changed chan struct{}
+
+	// closed by Stop(), after the EventStopped event
+	events  chan refresh.Event[T]
+	stopped bool
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// ensure Refresher implements refresh.Refresher
+var _ refresh.Refresher[any] = (*Refresher[any])(nil)
+
+// NewRefresher returns a Refresher with no value.
+func NewRefresher[T any]() *Refresher[T] {
+	return &Refresher[T]{
+		changed: make(chan struct{}),
+		events:  make(chan refresh.Event[T], refresh.DefaultEventBufferSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// NewRefresherWithValue returns a Refresher holding the given value, issued now
+// and expiring after the given lifetime.
+func NewRefresherWithValue[T any](value T, lifetime time.Duration) *Refresher[T] {
+	m := NewRefresher[T]()
+	now := time.Now()
+	m.SetCurrent(&refresh.Refreshable[T]{Value: value, IssuedAt: now, ExpiresAt: now.Add(lifetime)})
+	return m
+}
+
+// SetCurrent sets the current value, synchronously notifying subscribers of non-nil values.
+func (m *Refresher[T]) SetCurrent(current *refresh.Refreshable[T]) {
+	m.mu.Lock()
+	if m.current != nil {
+		m.previous = m.current
+	}
+	m.current = current
+	m.notifyLocked()
+	subscribers := make([]func(*refresh.Refreshable[T]), 0, len(m.subscribers))
+	for _, fn := range m.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	m.mu.Unlock()
+
+	if current == nil {
+		return
+	}
+	for _, fn := range subscribers {
+		fn(current)
+	}
+}
+
+// SetNextRefreshTime sets the time returned by GetNextRefreshTime.
+func (m *Refresher[T]) SetNextRefreshTime(nextRefreshAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextRefreshAt = nextRefreshAt
+}
+
+// SetRefreshFunc sets the function which provides new values on simulated refresh cycles.
+// If no function is set, simulated refresh cycles keep the current value. With
+// refresh.WithImmediateRefresh, a refresh cycle is simulated before SetRefreshFunc returns.
+func (m *Refresher[T]) SetRefreshFunc(refreshFunc refresh.RefreshFunc[T], opts ...refresh.SwapOption) {
+	m.mu.Lock()
+	m.refreshFunc = refreshFunc
+	m.mu.Unlock()
+
+	if refresh.NewSwapConfig(opts...).RefreshImmediately {
+		_, _ = m.Tick()
+	}
+}
+
+// SetRefreshStrategy sets the strategy which determines the next refresh time after successful
+// simulated refresh cycles. If no strategy is set, the time set with SetNextRefreshTime is kept.
+// With refresh.WithImmediateRefresh, a refresh cycle is simulated before SetRefreshStrategy returns.
+func (m *Refresher[T]) SetRefreshStrategy(strategy refresh.RefreshStrategy[T], opts ...refresh.SwapOption) {
+	m.mu.Lock()
+	m.strategy = strategy
+	m.mu.Unlock()
+
+	if refresh.NewSwapConfig(opts...).RefreshImmediately {
+		_, _ = m.Tick()
+	}
+}
+
+// SetRefreshError makes all subsequent simulated refresh cycles (and WaitForInitialValue,
+// while there is no value) fail with the given error. A nil error clears the failure.
+func (m *Refresher[T]) SetRefreshError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshErr = err
+	m.notifyLocked()
+}
+
+// Refreshes returns the number of simulated refresh cycles so far (successful or not).
+func (m *Refresher[T]) Refreshes() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.refreshes
+}
+
+// Tick simulates a refresh cycle, returning the resulting value or error.
+func (m *Refresher[T]) Tick() (*refresh.Refreshable[T], error) {
+	return m.tick(context.Background())
+}
+
+// tick simulates a refresh cycle.
+func (m *Refresher[T]) tick(ctx context.Context) (*refresh.Refreshable[T], error) {
+	m.mu.Lock()
+	refreshFunc, refreshErr := m.refreshFunc, m.refreshErr
+	m.refreshes++
+	m.mu.Unlock()
+
+	if refreshErr != nil {
+		m.recordTick(refreshErr)
+		m.Emit(refresh.Event[T]{Kind: refresh.EventRefreshFailed, Err: refreshErr})
+		return nil, refreshErr
+	}
+	if refreshFunc == nil {
+		m.recordTick(nil)
+		current := m.GetCurrentUnsafe()
+		m.Emit(refresh.Event[T]{Kind: refresh.EventRefreshSucceeded, Refreshable: current, RefreshAt: m.GetNextRefreshTime()})
+		return current, nil
+	}
+	newValue, err := refreshFunc(ctx)
+	if err == nil && newValue == nil {
+		err = refresh.ErrNilRefreshable // as per the default NilRefreshablePolicy
+	}
+	m.recordTick(err)
+	if err != nil {
+		m.Emit(refresh.Event[T]{Kind: refresh.EventRefreshFailed, Err: err})
+		return nil, err
+	}
+	m.SetCurrent(newValue)
+
+	m.mu.Lock()
+	if m.strategy != nil && newValue != nil {
+		m.nextRefreshAt = m.strategy.GetRefreshAt(newValue)
+	}
+	m.emitLocked(refresh.Event[T]{Kind: refresh.EventRefreshSucceeded, Refreshable: newValue, RefreshAt: m.nextRefreshAt})
+	m.mu.Unlock()
+	return newValue, nil
+}
+
+// Emit sends the given event on the channel returned by Events, e.g. to simulate storage
+// operations or expiries. Its Time is set to now unless set. Simulated refresh cycles emit
+// their own events. Like the events of refreshers, events are dropped if the buffer is full.
+func (m *Refresher[T]) Emit(event refresh.Event[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emitLocked(event)
+}
+
+// emitLocked sends an event on the events channel, unless the Refresher is stopped. m.mu must be held.
+func (m *Refresher[T]) emitLocked(event refresh.Event[T]) {
+	if m.stopped {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	select {
+	case m.events <- event:
+	default:
+	}
+}
+
+// Events returns the channel on which events are sent, which is closed by Stop.
+func (m *Refresher[T]) Events() <-chan refresh.Event[T] {
+	return m.events
+}
+
+// recordTick updates the Refresher's stats with the outcome of a simulated refresh cycle.
+func (m *Refresher[T]) recordTick(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.stats.Refreshes++
+	m.stats.LastRefreshAt = now
+	if err != nil {
+		m.stats.Failures++
+		m.stats.ConsecutiveFailures++
+		m.stats.LastFailureAt = now
+		m.stats.LastError = err
+		return
+	}
+	m.stats.ConsecutiveFailures = 0
+	m.stats.LastSuccessAt = now
+}
+
+// notifyLocked wakes up all WaitForInitialValue callers. m.mu must be held.
+func (m *Refresher[T]) notifyLocked() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// WaitForInitialValue returns as soon as a value is set, the refresh error is set,
+// or a timeout of the specified duration, whichever happens first.
+func (m *Refresher[T]) WaitForInitialValue(timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		m.mu.Lock()
+		current, refreshErr, changed := m.current, m.refreshErr, m.changed
+		m.mu.Unlock()
+
+		if current != nil {
+			return nil
+		}
+		if refreshErr != nil {
+			return &refresh.Error{Sentinel: refresh.ErrRefreshFailed, Cause: refreshErr}
+		}
+
+		select {
+		case <-timer.C:
+			return refresh.ErrInitTimeout
+		case <-changed:
+		}
+	}
+}
+
+// GetCurrent returns the current value.
+func (m *Refresher[T]) GetCurrent() *refresh.Refreshable[T] {
+	return m.GetCurrentUnsafe()
+}
+
+// GetCurrentFresh returns the current value, enforcing its expiry.
+func (m *Refresher[T]) GetCurrentFresh() (*refresh.Refreshable[T], error) {
+	current := m.GetCurrentUnsafe()
+	if current == nil {
+		return nil, refresh.ErrNoValue
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return current, refresh.ErrStale
+	}
+	return current, nil
+}
+
+// GetValue returns the current value, enforcing its expiry.
+func (m *Refresher[T]) GetValue() (T, error) {
+	var zero T
+	current, err := m.GetCurrentFresh()
+	if current == nil {
+		return zero, err
+	}
+	return current.Value, err
+}
+
+// MustValue returns the current value, or panics if there is none.
+func (m *Refresher[T]) MustValue() T {
+	current := m.GetCurrent()
+	if current == nil {
+		panic(fmt.Sprintf("refreshtest: MustValue called on a refresher without a value: %v", refresh.ErrNoValue))
+	}
+	return current.Value
+}
+
+// GetCurrentUnsafe returns the current value.
+func (m *Refresher[T]) GetCurrentUnsafe() *refresh.Refreshable[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current
+}
+
+// GetPrevious returns the value which was current before the current one.
+func (m *Refresher[T]) GetPrevious() *refresh.Refreshable[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.previous
+}
+
+// GetHistory returns the current and previous values which are not expired, newest first.
+func (m *Refresher[T]) GetHistory() []*refresh.Refreshable[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	history := []*refresh.Refreshable[T]{}
+	for _, refreshable := range []*refresh.Refreshable[T]{m.current, m.previous} {
+		if refreshable != nil && !now.After(refreshable.ExpiresAt) {
+			history = append(history, refreshable)
+		}
+	}
+	return history
+}
+
+// GetFresh returns the current value if it is not expired, otherwise it simulates a refresh cycle.
+func (m *Refresher[T]) GetFresh(ctx context.Context) (*refresh.Refreshable[T], error) {
+	if current, err := m.GetCurrentFresh(); err == nil {
+		return current, nil
+	}
+	return m.tick(ctx)
+}
+
+// ForceRefresh simulates a refresh cycle.
+func (m *Refresher[T]) ForceRefresh(ctx context.Context) (*refresh.Refreshable[T], error) {
+	return m.tick(ctx)
+}
+
+// GetNextRefreshTime returns the time set with SetNextRefreshTime.
+func (m *Refresher[T]) GetNextRefreshTime() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nextRefreshAt
+}
+
+// Pause marks the Refresher as paused.
+func (m *Refresher[T]) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = true
+}
+
+// Resume marks the Refresher as not paused.
+func (m *Refresher[T]) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = false
+}
+
+// Paused returns true if the Refresher is paused.
+func (m *Refresher[T]) Paused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
+// Stop marks the Refresher as stopped, emitting its EventStopped event.
+func (m *Refresher[T]) Stop() {
+	m.stopOnce.Do(func() {
+		m.mu.Lock()
+		m.emitLocked(refresh.Event[T]{Kind: refresh.EventStopped, Err: refresh.ErrStopped})
+		m.stopped = true
+		close(m.events)
+		m.mu.Unlock()
+
+		close(m.done)
+	})
+}
+
+// StopAndWait marks the Refresher as stopped.
+func (m *Refresher[T]) StopAndWait(context.Context) error {
+	m.Stop()
+	return nil
+}
+
+// Done returns a channel which is closed when the Refresher is stopped.
+func (m *Refresher[T]) Done() <-chan struct{} {
+	return m.done
+}
+
+// Cause returns refresh.ErrStopped if the Refresher is stopped, or nil otherwise.
+func (m *Refresher[T]) Cause() error {
+	select {
+	case <-m.done:
+		return refresh.ErrStopped
+	default:
+		return nil
+	}
+}
+
+// Stats returns counters about the simulated refresh cycles so far.
+func (m *Refresher[T]) Stats() refresh.Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// Subscribe registers a function which is called with every value set with SetCurrent
+// (including by simulated refresh cycles), until the returned function is called.
+func (m *Refresher[T]) Subscribe(fn func(*refresh.Refreshable[T])) (unsubscribe func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.subscribers == nil {
+		m.subscribers = make(map[uint64]func(*refresh.Refreshable[T]))
+	}
+	id := m.nextSubscriberID
+	m.nextSubscriberID++
+	m.subscribers[id] = fn
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subscribers, id)
+	}
+}
@@ -0,0 +1,317 @@
+package refreshtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// StressConfig is the configuration of a stress run.
+type StressConfig struct {
+	// Seed seeds the run's randomness, such that failing runs can be reproduced
+	// (modulo go-routine scheduling). Defaults to 1.
+	Seed int64
+
+	// Goroutines is the number of go-routines concurrently operating on the
+	// refresher. Defaults to 8.
+	Goroutines int
+
+	// Operations is the number of random operations carried out by each
+	// go-routine. Defaults to 200.
+	Operations int
+
+	// FailureRate is the probability (in [0, 1]) of any refresh or storage
+	// operation failing. Defaults to 0 (no failures).
+	FailureRate float64
+
+	// Lifetime is the lifetime of refreshed values, from which all of the run's latencies
+	// and timeouts are derived. It must be at least MinStressLifetime. Defaults to 50ms.
+	Lifetime time.Duration
+}
+
+// MinStressLifetime is the shortest Lifetime of a stress run.
+const MinStressLifetime = time.Millisecond
+
+// Stress runs randomized sequences of operations (reads, forced refreshes, pauses, resumes,
+// subscriptions, and stops) from concurrent go-routines against a refresher created with the
+// given Option(s) plus a flaky RefreshFunc and Storage, and returns an error describing the
+// first violated invariant, if any. One of the go-routines stops the refresher (with Stop or
+// StopAndWait) at a random point in its second half of operations, while the others keep
+// operating on it. The invariants checked are:
+//
+//   - the values observed by any single go-routine or subscriber never go back in time
+//   - values returned by GetFresh are not expired
+//   - at most one RefreshFunc invocation is in progress at any time
+//   - no RefreshFunc invocation starts, and no subscriber is called, after StopAndWait returns
+//
+// It is meant to be run in tests under the race detector, e.g.
+//
+//	func TestStress(t *testing.T) {
+//		if err := refreshtest.Stress(refreshtest.StressConfig{FailureRate: 0.2}); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func Stress(cfg StressConfig, opts ...refresh.Option[int64]) error {
+	if cfg.Seed == 0 {
+		cfg.Seed = 1
+	}
+	if cfg.Goroutines <= 0 {
+		cfg.Goroutines = 8
+	}
+	if cfg.Operations <= 0 {
+		cfg.Operations = 200
+	}
+	if cfg.Lifetime <= 0 {
+		cfg.Lifetime = 50 * time.Millisecond
+	}
+	if cfg.Lifetime < MinStressLifetime {
+		return fmt.Errorf("lifetime %s is shorter than %s", cfg.Lifetime, MinStressLifetime)
+	}
+
+	h := &stressHarness{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed))}
+	stopper := h.rng.Intn(cfg.Goroutines)
+	stopAt := cfg.Operations/2 + h.rng.Intn(cfg.Operations-cfg.Operations/2)
+
+	opts = append([]refresh.Option[int64]{
+		refresh.WithRetryDelay[int64](cfg.Lifetime / 10),
+		refresh.WithRefreshStrategy(refresh.RefreshStrategyFromFunction(func(r *refresh.Refreshable[int64]) time.Time {
+			return r.IssuedAt.Add(r.ExpiresAt.Sub(r.IssuedAt) / 2)
+		})),
+		refresh.WithStorage(refresh.StorageFromFunctions(h.storageGet, h.storagePut)),
+	}, opts...)
+	r := refresh.NewRefresher(h.refreshFunc, opts...)
+
+	_ = r.WaitForInitialValue(10 * cfg.Lifetime) // failures are fine, the refresher retries
+
+	var wg sync.WaitGroup
+	for g := 0; g < cfg.Goroutines; g++ {
+		wg.Add(1)
+		operationsBeforeStop := -1 // never stops
+		if g == stopper {
+			operationsBeforeStop = stopAt
+		}
+		go func(seed int64, operationsBeforeStop int) {
+			defer wg.Done()
+			h.operate(r, rand.New(rand.NewSource(seed)), operationsBeforeStop)
+		}(cfg.Seed+int64(g)+1, operationsBeforeStop)
+	}
+	wg.Wait()
+
+	h.stopAndWait(r)
+	startedAtStop := h.started.Load()
+	time.Sleep(2 * cfg.Lifetime)
+	if started := h.started.Load(); started != startedAtStop {
+		h.violate(fmt.Errorf("%d refresh(es) started after stopping", started-startedAtStop))
+	}
+	if !errors.Is(r.Cause(), refresh.ErrStopped) {
+		h.violate(fmt.Errorf("unexpected cause after stopping: %v", r.Cause()))
+	}
+	return h.violation()
+}
+
+// stressHarness holds the state of a stress run.
+type stressHarness struct {
+	cfg StressConfig
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+
+	counter  atomic.Int64
+	started  atomic.Int64
+	inFlight atomic.Int32
+
+	// set once StopAndWait has returned
+	stopped atomic.Bool
+
+	stored atomic.Pointer[refresh.Refreshable[int64]]
+
+	violationMu    sync.Mutex
+	firstViolation error
+}
+
+// stopAndWait stops the refresher and waits for it to be done, after which
+// no refresh may start and no subscriber may be called.
+func (h *stressHarness) stopAndWait(r refresh.Refresher[int64]) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*h.cfg.Lifetime)
+	defer cancel()
+	if err := r.StopAndWait(ctx); err != nil {
+		h.violate(fmt.Errorf("refresher did not stop in time: %v", err))
+		return
+	}
+	h.stopped.Store(true)
+}
+
+// fail returns true with the configured failure rate.
+func (h *stressHarness) fail() bool {
+	h.rngMu.Lock()
+	defer h.rngMu.Unlock()
+	return h.rng.Float64() < h.cfg.FailureRate
+}
+
+// latency returns a random latency for a simulated operation.
+func (h *stressHarness) latency() time.Duration {
+	h.rngMu.Lock()
+	defer h.rngMu.Unlock()
+	return randomDuration(h.rng, h.cfg.Lifetime/10)
+}
+
+// randomDuration returns a random duration in [0, d), or zero if d is not positive.
+func randomDuration(rng *rand.Rand, d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rng.Int63n(int64(d)))
+}
+
+// refreshFunc is a flaky RefreshFunc returning increasing values.
+func (h *stressHarness) refreshFunc(ctx context.Context) (*refresh.Refreshable[int64], error) {
+	h.started.Add(1)
+	if h.stopped.Load() {
+		h.violate(errors.New("refresh started after StopAndWait returned"))
+	}
+	if n := h.inFlight.Add(1); n > 1 {
+		h.violate(fmt.Errorf("%d refreshes in progress at once", n))
+	}
+	defer h.inFlight.Add(-1)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(h.latency()):
+	}
+	if h.fail() {
+		return nil, errors.New("simulated refresh failure")
+	}
+	now := time.Now()
+	return &refresh.Refreshable[int64]{Value: h.counter.Add(1), IssuedAt: now, ExpiresAt: now.Add(h.cfg.Lifetime)}, nil
+}
+
+// storageGet is a flaky Storage getter.
+func (h *stressHarness) storageGet(context.Context) (*refresh.Refreshable[int64], error) {
+	if h.fail() {
+		return nil, errors.New("simulated storage read failure")
+	}
+	if stored := h.stored.Load(); stored != nil {
+		return stored, nil
+	}
+	return nil, refresh.ErrNotStored
+}
+
+// storagePut is a flaky Storage putter.
+func (h *stressHarness) storagePut(_ context.Context, refreshable *refresh.Refreshable[int64]) error {
+	if h.fail() {
+		return errors.New("simulated storage write failure")
+	}
+	h.stored.Store(refreshable)
+	return nil
+}
+
+// subscribe subscribes to the refresher's values, checking that they never go back
+// in time and that they are not delivered after StopAndWait returned.
+func (h *stressHarness) subscribe(r refresh.Refresher[int64]) (unsubscribe func()) {
+	var last int64 // subscribers are never called concurrently with themselves
+	return r.Subscribe(func(refreshable *refresh.Refreshable[int64]) {
+		if h.stopped.Load() {
+			h.violate(fmt.Errorf("subscriber called with value %d after StopAndWait returned", refreshable.Value))
+		}
+		if refreshable.Value < last {
+			h.violate(fmt.Errorf("subscriber called with value %d after value %d", refreshable.Value, last))
+		}
+		last = refreshable.Value
+	})
+}
+
+// operate carries out random operations against the refresher, stopping
+// it after the given number of operations (unless it is negative).
+func (h *stressHarness) operate(r refresh.Refresher[int64], rng *rand.Rand, operationsBeforeStop int) {
+	var last int64
+	observe := func(refreshable *refresh.Refreshable[int64]) {
+		if refreshable == nil {
+			return
+		}
+		if refreshable.Value < last {
+			h.violate(fmt.Errorf("observed value %d after value %d", refreshable.Value, last))
+		}
+		last = refreshable.Value
+	}
+
+	var unsubscribes []func()
+	defer func() {
+		for _, unsubscribe := range unsubscribes {
+			unsubscribe()
+		}
+	}()
+
+	for i := 0; i < h.cfg.Operations; i++ {
+		if i == operationsBeforeStop {
+			if rng.Intn(2) == 0 {
+				r.Stop()
+			} else {
+				h.stopAndWait(r)
+			}
+		}
+		switch rng.Intn(12) {
+		case 0, 1, 2:
+			observe(r.GetCurrent())
+		case 3:
+			observe(r.GetCurrentUnsafe())
+		case 4:
+			current, _ := r.GetCurrentFresh()
+			observe(current)
+		case 5, 6:
+			ctx, cancel := context.WithTimeout(context.Background(), h.cfg.Lifetime)
+			began := time.Now()
+			current, err := r.GetFresh(ctx)
+			cancel()
+			if err == nil {
+				if current.ExpiresAt.Before(began) {
+					h.violate(fmt.Errorf("GetFresh returned value %d which expired at %s", current.Value, current.ExpiresAt))
+				}
+				observe(current)
+			}
+		case 7:
+			ctx, cancel := context.WithTimeout(context.Background(), h.cfg.Lifetime)
+			current, err := r.ForceRefresh(ctx)
+			cancel()
+			if err == nil {
+				observe(current)
+			}
+		case 8:
+			r.Pause()
+		case 9:
+			r.Resume()
+		case 10:
+			unsubscribes = append(unsubscribes, h.subscribe(r))
+		case 11:
+			if len(unsubscribes) > 0 {
+				j := rng.Intn(len(unsubscribes))
+				unsubscribes[j]()
+				unsubscribes = append(unsubscribes[:j], unsubscribes[j+1:]...)
+			}
+		}
+		time.Sleep(randomDuration(rng, h.cfg.Lifetime/20))
+	}
+	r.Resume()
+}
+
+// violate records an invariant violation.
+func (h *stressHarness) violate(err error) {
+	h.violationMu.Lock()
+	defer h.violationMu.Unlock()
+	if h.firstViolation == nil {
+		h.firstViolation = err
+	}
+}
+
+// violation returns the first recorded invariant violation, if any.
+func (h *stressHarness) violation() error {
+	h.violationMu.Lock()
+	defer h.violationMu.Unlock()
+	return h.firstViolation
+}
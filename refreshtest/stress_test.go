@@ -0,0 +1,35 @@
+package refreshtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+func TestStress(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		for _, failureRate := range []float64{0, 0.2, 0.5} {
+			for name, opts := range map[string][]refresh.Option[int64]{
+				"default":       nil,
+				"sync handlers": {refresh.WithSynchronousCallbacks[int64]()},
+				"lazy start":    {refresh.WithLazyStart[int64]()},
+			} {
+				t.Run(fmt.Sprintf("%s/seed=%d/failures=%.1f", name, seed, failureRate), func(t *testing.T) {
+					t.Parallel()
+					cfg := StressConfig{Seed: seed, Goroutines: 4, Operations: 100, FailureRate: failureRate}
+					if err := Stress(cfg, opts...); err != nil {
+						t.Fatal(err)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestStressRejectsShortLifetime(t *testing.T) {
+	if err := Stress(StressConfig{Lifetime: 10 * time.Nanosecond}); err == nil {
+		t.Fatal("expected an error for a lifetime shorter than MinStressLifetime")
+	}
+}
@@ -0,0 +1,92 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// RenewFunc renews the given (current) value, returning the renewed value as well
+// as when it expires. Renewal is typically much cheaper than re-issuance, e.g. the
+// renewal of a lease versus the acquisition of a brand new secret.
+type RenewFunc[T any] func(ctx context.Context, current *Refreshable[T]) (*Refreshable[T], error)
+
+// RenewDecisionFunc decides, at refresh time, whether the current value should be
+// renewed (true) rather than re-issued (false), given how many more renewals are
+// allowed (a negative number meaning unlimited renewals).
+type RenewDecisionFunc[T any] func(current *Refreshable[T], renewalsLeft int) bool
+
+// WithRenewal is the refresher Option to renew the current value, rather than acquire
+// a new one with the RefreshFunc, whenever the given RenewDecisionFunc allows it and at
+// most maxRenewals times in a row (a negative maxRenewals allows unlimited renewals).
+// A nil RenewDecisionFunc renews values for as long as they have not expired.
+//
+// If a renewal fails (or the renewed value is invalid, see WithValidator), the failure is
+// logged and the refresher falls back to re-issuing the value with the RefreshFunc within
+// the same refresh attempt. Every re-issuance resets the count of renewals left.
+func WithRenewal[T any](renew RenewFunc[T], maxRenewals int, decide RenewDecisionFunc[T]) Option[T] {
+	return func(r *refresher[T]) {
+		if decide == nil {
+			decide = RenewWhileUnexpired[T]
+		}
+		r.renewFunc = renew
+		r.renewDecision = decide
+		r.maxRenewals = maxRenewals
+	}
+}
+
+// RenewWhileUnexpired is a RenewDecisionFunc which renews values
+// for as long as they have not expired and renewals are left.
+func RenewWhileUnexpired[T any](current *Refreshable[T], renewalsLeft int) bool {
+	return renewalsLeft != 0 && !isExpired(current, time.Now())
+}
+
+// acquire returns a new value, either by renewing the current value or by re-issuing it
+// (falling back to the fallback RefreshFuncs, if any), as per the refresher's configuration.
+// It also returns the source of the new value, as reported by Event.Source.
+//
+// It must only ever be called by refresh() such that calls are serialized.
+func (r *refresher[T]) acquire(ctx context.Context) (*Refreshable[T], int, error) {
+	ctx = r.refreshContext(ctx)
+	current := r.getCurrent()
+	if r.renewFunc != nil && current != nil {
+		renewalsLeft := -1
+		if r.maxRenewals >= 0 {
+			renewalsLeft = r.maxRenewals - r.renewals
+		}
+		if renewalsLeft != 0 && r.renewDecision(current, renewalsLeft) {
+			renewed, err := r.renew(ctx, current)
+			if err == nil {
+				r.renewals++
+				return renewed, 0, nil
+			}
+			r.log(slog.LevelWarn, "renewal failed, re-issuing value", "error", err, "renewals", r.renewals)
+		}
+	}
+	source := 0
+	reissued, err := r.reissue(ctx, r.refreshFunc, current)
+	if err != nil && len(r.fallbacks) > 0 {
+		reissued, source, err = r.fallback(ctx, current, err)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	r.renewals = 0
+	return reissued, source, nil
+}
+
+// renew renews the current value with the refresher's RenewFunc, and validates the renewed value.
+func (r *refresher[T]) renew(ctx context.Context, current *Refreshable[T]) (*Refreshable[T], error) {
+	renewed, err := r.renewFunc(ctx, current)
+	if err == nil && renewed == nil {
+		err = errors.New("renew function returned neither a value nor an error")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.validate(renewed); err != nil {
+		return nil, err
+	}
+	return renewed, nil
+}
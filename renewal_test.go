@@ -0,0 +1,101 @@
+package refresh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use, e.g. by loggers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRenewalFallsBackToReissue(t *testing.T) {
+	refreshFunc, issued := counter(time.Hour)
+	var renewed atomic.Int64
+	var renewErr atomic.Pointer[error]
+	renewFunc := func(_ context.Context, current *Refreshable[int]) (*Refreshable[int], error) {
+		if err := renewErr.Load(); err != nil {
+			return nil, *err
+		}
+		renewed.Add(1)
+		now := time.Now()
+		return &Refreshable[int]{Value: -current.Value, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+	}
+	var invalidRenewals atomic.Bool
+	validator := func(r *Refreshable[int]) error {
+		if r.Value < 0 && invalidRenewals.Load() {
+			return errors.New("renewed value rejected")
+		}
+		return nil
+	}
+	logs := &syncBuffer{}
+
+	r := NewRefresher(refreshFunc,
+		WithRenewal(renewFunc, 2, nil),
+		WithValidator[int](validator),
+		WithLogger[int](slog.New(slog.NewTextHandler(logs, nil))),
+	)
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	refresh := func(expectIssued, expectRenewed int64, expectRenewals int) {
+		t.Helper()
+		if _, err := r.ForceRefresh(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if n := issued.Load(); n != expectIssued {
+			t.Errorf("value issued %d times, expected %d", n, expectIssued)
+		}
+		if n := renewed.Load(); n != expectRenewed {
+			t.Errorf("value renewed %d times, expected %d", n, expectRenewed)
+		}
+		if n := r.(*refresher[int]).renewals; n != expectRenewals {
+			t.Errorf("%d renewals in a row, expected %d", n, expectRenewals)
+		}
+	}
+
+	refresh(1, 1, 1)
+	refresh(1, 2, 2)
+	refresh(2, 2, 0) // out of renewals, re-issued
+	refresh(2, 3, 1)
+
+	err := errors.New("lease revoked")
+	renewErr.Store(&err)
+	refresh(3, 3, 0) // renewal failed, re-issued
+	if !strings.Contains(logs.String(), "lease revoked") {
+		t.Errorf("renewal failure not logged:\n%s", logs)
+	}
+
+	renewErr.Store(nil)
+	invalidRenewals.Store(true)
+	refresh(4, 4, 0) // renewed value invalid, re-issued
+	if !strings.Contains(logs.String(), "renewed value rejected") {
+		t.Errorf("invalid renewal not logged:\n%s", logs)
+	}
+	if current := r.GetCurrent(); current.Value != 4 {
+		t.Errorf("current value is %d, expected the re-issued value", current.Value)
+	}
+}
@@ -0,0 +1,75 @@
+package refresh
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// ErrNilRefreshable is the cause of refresh failures for which the RefreshFunc returned neither
+// a value nor an error, unless the refresher's NilRefreshablePolicy decides otherwise.
+var ErrNilRefreshable = errors.New("refresh function returned neither a value nor an error")
+
+// NilRefreshablePolicy decides how to handle a RefreshFunc returning neither a value nor an error,
+// given the refresher's current value (nil if there is none). It returns the value to use instead,
+// or the error to fail the refresh with. A policy returning neither fails the refresh with
+// ErrNilRefreshable.
+type NilRefreshablePolicy[T any] func(current *Refreshable[T]) (*Refreshable[T], error)
+
+// NilRefreshableIsError is the default NilRefreshablePolicy, which fails the refresh with ErrNilRefreshable.
+func NilRefreshableIsError[T any](*Refreshable[T]) (*Refreshable[T], error) {
+	return nil, ErrNilRefreshable
+}
+
+// WithNilRefreshablePolicy is the refresher Option to override the default NilRefreshablePolicy
+// (NilRefreshableIsError), e.g. to retry sooner than after the retry delay by returning a
+// *RetryAfterError. Note that a value returned by the policy is handled as a refreshed value,
+// so returning a current value which is due for refresh results in an immediate refresh.
+func WithNilRefreshablePolicy[T any](policy NilRefreshablePolicy[T]) Option[T] {
+	return func(r *refresher[T]) { r.nilRefreshablePolicy = policy }
+}
+
+// PanicError is the error reported for a panic recovered by a refresher. Refreshers recover
+// from panics in the RefreshFunc (and RenewFunc, middleware, and validators), in which case
+// the refresh fails with a *PanicError, as well as in RefreshStrategies, event handlers,
+// observers, and subscribers, such that a single faulty function does not crash the process.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the go-routine which panicked, as per debug.Stack.
+	Stack []byte
+}
+
+// Error returns the error's message.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered from panic: %v", e.Value)
+}
+
+// WithOnPanic is the refresher Option to set a callback function to be fired
+// whenever the refresher recovers from a panic, e.g. to report it.
+func WithOnPanic[T any](onPanic func(*PanicError)) Option[T] {
+	return func(r *refresher[T]) { r.onPanic = onPanic }
+}
+
+// guard calls the given function, recovering from (and reporting) any panic, in
+// which case it returns a *PanicError. The given description names the function.
+func (r *refresher[T]) guard(description string, fn func()) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicErr := &PanicError{Value: recovered, Stack: debug.Stack()}
+			r.log(slog.LevelError, "recovered from panic", "in", description, "panic", recovered)
+			r.reportPanic(panicErr)
+			err = panicErr
+		}
+	}()
+	fn()
+	return nil
+}
+
+// reportPanic fires the refresher's panic callback, ignoring panics in the callback itself.
+func (r *refresher[T]) reportPanic(panicErr *PanicError) {
+	defer func() { _ = recover() }()
+	r.onPanic(panicErr)
+}
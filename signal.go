@@ -0,0 +1,48 @@
+package refresh
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// RefreshOnSignal forces an immediate refresh of the given Refresher whenever the process
+// receives any of the given signals, following the convention of reloading credentials and
+// certificates on SIGHUP:
+//
+//	stop := refresh.RefreshOnSignal(r, syscall.SIGHUP)
+//	defer stop()
+//
+// Signals received while a refresh is in progress join that refresh. The returned function
+// stops listening for the signals, which also happens once the Refresher stops. Note that while
+// listening, the signals no longer have their default behavior (e.g. SIGHUP no longer terminates
+// the process), as with signal.Notify.
+func RefreshOnSignal[T any](r Refresher[T], sigs ...os.Signal) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sigs...)
+
+	stopped := make(chan struct{})
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(stopped)
+		})
+	}
+
+	go func() {
+		defer stop()
+		for {
+			select {
+			case <-stopped:
+				return
+			case <-r.Done():
+				return
+			case <-signals:
+				_, _ = r.ForceRefresh(context.Background())
+			}
+		}
+	}()
+	return stop
+}
@@ -0,0 +1,43 @@
+package refresh
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Sink represents a destination to which every new value of a refresher is
+// written, e.g. files on disk read by another process (see package filesink).
+type Sink[T any] interface {
+	// Write writes the given Refreshable. The given context is
+	// cancelled once the refresher is stopped.
+	Write(ctx context.Context, refreshable *Refreshable[T]) error
+}
+
+// WithSink is the refresher Option to add a Sink, to which every new value (refreshed
+// or read from storage) is written. Writes are carried out like event handlers (see
+// WithSynchronousCallbacks), in order, and their outcome is reported as EventSinkWrite
+// events. This Option can be provided multiple times to add multiple Sinks.
+func WithSink[T any](sink Sink[T]) Option[T] {
+	return func(r *refresher[T]) { r.sinks = append(r.sinks, sink) }
+}
+
+// writeSinks writes a new value to every sink. It must only
+// be called from the refresher's own (tracked) go-routines.
+func (r *refresher[T]) writeSinks(newValue *Refreshable[T]) {
+	for _, sink := range r.sinks {
+		r.emit(func() { r.writeSink(sink, newValue) })
+	}
+}
+
+// writeSink writes a value to a sink and reports the outcome.
+func (r *refresher[T]) writeSink(sink Sink[T], refreshable *Refreshable[T]) {
+	began := time.Now()
+	if err := sink.Write(r.refreshCtx, refreshable); err != nil {
+		r.log(slog.LevelWarn, "sink write failed", "error", err)
+		r.publish(Event[T]{Kind: EventSinkWrite, Duration: time.Since(began), Err: err})
+		return
+	}
+	r.log(slog.LevelDebug, "wrote value to sink", "expires_at", refreshable.ExpiresAt)
+	r.publish(Event[T]{Kind: EventSinkWrite, Refreshable: refreshable, Duration: time.Since(began)})
+}
@@ -0,0 +1,52 @@
+package refresh
+
+import "time"
+
+// StalePolicy determines what a Refresher hands out when its current value is expired,
+// which typically happens after repeated refresh failures.
+type StalePolicy int
+
+const (
+	// StalePolicyReturnStale hands out expired values as-is. GetCurrent returns
+	// the expired value and GetCurrentFresh returns it alongside ErrStale.
+	// This is the default policy.
+	StalePolicyReturnStale StalePolicy = iota
+
+	// StalePolicyReturnError withholds expired values. GetCurrent returns
+	// nil and GetCurrentFresh returns nil alongside ErrStale.
+	StalePolicyReturnError
+
+	// StalePolicyReturnNil withholds expired values silently. Both GetCurrent
+	// and GetCurrentFresh return nil (with a nil error).
+	StalePolicyReturnNil
+)
+
+// WithStalePolicy is the refresher Option to set the StalePolicy applied
+// when the current value is expired. The default is StalePolicyReturnStale.
+func WithStalePolicy[T any](stalePolicy StalePolicy) Option[T] {
+	return func(r *refresher[T]) { r.stalePolicy = stalePolicy }
+}
+
+// isExpired returns true if the given Refreshable is expired at the given time.
+func isExpired[T any](refreshable *Refreshable[T], now time.Time) bool {
+	return now.After(refreshable.ExpiresAt)
+}
+
+// applyStalePolicy returns the value and error to hand out for the given
+// Refreshable as per the refresher's StalePolicy.
+func (r *refresher[T]) applyStalePolicy(current *Refreshable[T]) (*Refreshable[T], error) {
+	if current == nil {
+		return nil, r.wrapErr(ErrNoValue, nil)
+	}
+	if !isExpired(current, time.Now()) {
+		return current, nil
+	}
+	switch r.stalePolicy {
+	case StalePolicyReturnError:
+		return nil, r.wrapErr(ErrStale, nil)
+	case StalePolicyReturnNil:
+		return nil, nil
+	default:
+		return current, r.wrapErr(ErrStale, nil)
+	}
+}
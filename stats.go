@@ -0,0 +1,69 @@
+package refresh
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Stats are counters about the refresh attempts of a Refresher.
+type Stats struct {
+	// Refreshes is the total number of refresh attempts, successful or not.
+	Refreshes int64
+
+	// Failures is the total number of failed refresh attempts.
+	Failures int64
+
+	// ConsecutiveFailures is the number of failed refresh
+	// attempts since the last successful one.
+	ConsecutiveFailures int64
+
+	// LastRefreshAt is when the last refresh attempt (successful or not) completed.
+	LastRefreshAt time.Time
+
+	// LastSuccessAt is when the last successful refresh attempt completed.
+	LastSuccessAt time.Time
+
+	// LastFailureAt and LastError are when the last failed refresh attempt
+	// completed and its error. They are kept after subsequent successful attempts.
+	LastFailureAt time.Time
+	LastError     error
+
+	// Exhausted is true if the Refresher gave up on background refreshes after
+	// too many consecutive failures (see WithMaxConsecutiveFailures).
+	Exhausted bool
+}
+
+// Stats returns counters about the refresher's refresh attempts.
+func (r *refresher[T]) Stats() Stats {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	return r.stats
+}
+
+// recordRefresh updates the refresher's stats with the outcome of a refresh attempt.
+func (r *refresher[T]) recordRefresh(err error) {
+	r.statsMu.Lock()
+	now := time.Now()
+	r.stats.Refreshes++
+	r.stats.LastRefreshAt = now
+	if err == nil {
+		r.stats.ConsecutiveFailures = 0
+		r.stats.LastSuccessAt = now
+		r.stats.Exhausted = false
+		r.statsMu.Unlock()
+		return
+	}
+	r.stats.Failures++
+	r.stats.ConsecutiveFailures++
+	r.stats.LastFailureAt = now
+	r.stats.LastError = err
+	exhausted := r.exhaustLocked()
+	r.statsMu.Unlock()
+
+	if exhausted {
+		r.log(slog.LevelError, "giving up on background refreshes after consecutive failures",
+			"failures", r.maxConsecutiveFailures, "error", err)
+		r.emit(func() { r.onExhausted(err) })
+	}
+}
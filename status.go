@@ -0,0 +1,88 @@
+package refresh
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// statusResponse is the JSON representation of the Statuses of a Manager's Refreshers.
+type statusResponse struct {
+	Healthy    bool         `json:"healthy"`
+	Refreshers []statusJSON `json:"refreshers"`
+}
+
+// statusJSON is the JSON representation of a Status.
+type statusJSON struct {
+	Name                string     `json:"name"`
+	Healthy             bool       `json:"healthy"`
+	Started             bool       `json:"started"`
+	Error               string     `json:"error,omitempty"`
+	IssuedAt            *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt           *time.Time `json:"expires_at,omitempty"`
+	NextRefreshAt       *time.Time `json:"next_refresh_at,omitempty"`
+	Refreshes           int64      `json:"refreshes"`
+	Failures            int64      `json:"failures"`
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	LastRefreshAt       *time.Time `json:"last_refresh_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	Exhausted           bool       `json:"exhausted,omitempty"`
+	Summary             any        `json:"summary,omitempty"`
+}
+
+// StatusHandler returns an http.Handler which serves the Status of every Refresher registered
+// in the given Manager as JSON, e.g. for a /debug/refresh endpoint. Values are not included,
+// only their summaries (see WithValueSummary and WithRedactor).
+//
+// The response status is always 200 OK, with the aggregate health in the "healthy" field.
+func StatusHandler(m *Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		resp := &statusResponse{Healthy: true, Refreshers: []statusJSON{}}
+		m.Range(func(status Status) bool {
+			s := statusJSON{
+				Name:                status.Name,
+				Healthy:             true,
+				Started:             status.Started,
+				NextRefreshAt:       timeOrNil(status.NextRefreshAt),
+				Refreshes:           status.Stats.Refreshes,
+				Failures:            status.Stats.Failures,
+				ConsecutiveFailures: status.Stats.ConsecutiveFailures,
+				Exhausted:           status.Stats.Exhausted,
+				LastRefreshAt:       timeOrNil(status.Stats.LastRefreshAt),
+				LastSuccessAt:       timeOrNil(status.Stats.LastSuccessAt),
+				LastFailureAt:       timeOrNil(status.Stats.LastFailureAt),
+				Summary:             status.Summary,
+			}
+			if status.HasValue {
+				s.IssuedAt = timeOrNil(status.IssuedAt)
+				s.ExpiresAt = timeOrNil(status.ExpiresAt)
+			}
+			if err := status.Err(); err != nil {
+				s.Healthy = false
+				s.Error = err.Error()
+				resp.Healthy = false
+			}
+			if status.Stats.LastError != nil {
+				s.LastError = status.Stats.LastError.Error()
+			}
+			resp.Refreshers = append(resp.Refreshers, s)
+			return true
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(resp)
+	})
+}
+
+// timeOrNil returns a pointer to the given time, or nil if it is the zero time.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -2,8 +2,14 @@ package refresh
 
 import (
 	"context"
+	"errors"
+	"time"
 )
 
+// ErrNotStored is matched (with errors.Is) by the errors Storage implementations return from
+// Get when no value has been stored yet. Such reads are not retried (see WithStorageReadRetry).
+var ErrNotStored = errors.New("no stored data")
+
 // Storage represents a mechanism for persisting values
 // across restarts of an application using a Refresher.
 type Storage[T any] interface {
@@ -34,3 +40,54 @@ func StorageFromFunctions[T any](
 ) Storage[T] {
 	return &storage[T]{getFunc: getFunc, putFunc: putFunc}
 }
+
+// StorageHit represents the outcome of successfully reading a value from Storage
+// during initialization.
+type StorageHit int
+
+const (
+	// StorageHitFresh means the value read from Storage was fresh,
+	// and became the refresher's current value.
+	StorageHitFresh StorageHit = iota
+
+	// StorageHitStale means the value read from Storage was due for refresh (or had
+	// less remaining lifetime than the minimum storage freshness), and was discarded
+	// in favour of a freshly acquired value.
+	StorageHitStale
+)
+
+// String returns the name of the StorageHit.
+func (h StorageHit) String() string {
+	switch h {
+	case StorageHitFresh:
+		return "fresh"
+	case StorageHitStale:
+		return "stale"
+	default:
+		return "unknown"
+	}
+}
+
+// WithMinStorageFreshness is the refresher Option to treat values read from Storage with less
+// than the given remaining lifetime as a miss, acquiring a fresh value right away rather than
+// starting out with a value which is about to expire.
+func WithMinStorageFreshness[T any](minStorageFreshness time.Duration) Option[T] {
+	return func(r *refresher[T]) { r.minStorageFreshness = minStorageFreshness }
+}
+
+// WithOnStorageHit is the refresher Option to set a callback function to be fired after a value
+// is successfully read from Storage during initialization, distinguishing fresh values (which
+// are used) from stale ones (which are replaced with a freshly acquired value).
+func WithOnStorageHit[T any](onStorageHit func(*Refreshable[T], StorageHit)) Option[T] {
+	return func(r *refresher[T]) { r.onStorageHit = onStorageHit }
+}
+
+// isFreshFromStorage returns true if a value read from Storage
+// (due for refresh at the given time) may be used as is.
+func (r *refresher[T]) isFreshFromStorage(refreshable *Refreshable[T], refreshAt time.Time) bool {
+	now := time.Now()
+	if !now.Before(refreshAt) {
+		return false
+	}
+	return refreshable.ExpiresAt.Sub(now) >= r.minStorageFreshness
+}
@@ -0,0 +1,75 @@
+// Package azurekeyvault provides a refresh.Storage which persists
+// refreshed values as an Azure Key Vault secret.
+//
+// The Client interface mirrors the GetSecret and SetSecret methods of an
+// azsecrets.Client, with plain string values and 404s as storage.ErrNotFound.
+//
+// Since Key Vault secret values are strings, serialized data is stored base64 encoded.
+package azurekeyvault
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/storage"
+)
+
+// Client represents access to the secrets of a Key Vault.
+type Client interface {
+	// GetSecret returns the value of the latest version of the named
+	// secret, or an error matching storage.ErrNotFound if it does not exist.
+	GetSecret(ctx context.Context, name string) (string, error)
+
+	// SetSecret sets the value of the named secret, creating a new version of it.
+	SetSecret(ctx context.Context, name, value string) error
+}
+
+// NotFoundError is the error returned when a secret does not exist.
+type NotFoundError struct {
+	Secret string
+}
+
+// Error returns the error's message.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("secret %q not found", e.Secret)
+}
+
+// Is returns true for storage.ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == storage.ErrNotFound
+}
+
+// New returns a refresh.Storage which persists Refreshables serialized with the given Codec
+// as the named secret. Secret names may only contain alphanumeric characters and dashes.
+func New[T any](client Client, secret string, codec storage.Codec[T], opts ...storage.Option[T]) refresh.Storage[T] {
+	return storage.New(NewBackend(client, secret), codec, opts...)
+}
+
+// NewBackend returns a storage.Backend which stores data as the named secret.
+func NewBackend(client Client, secret string) storage.Backend {
+	return storage.BackendFromFunctions(
+		func(ctx context.Context) ([]byte, error) {
+			value, err := client.GetSecret(ctx, secret)
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, &NotFoundError{Secret: secret}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get secret %q: %v", secret, err)
+			}
+			data, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode secret %q: %v", secret, err)
+			}
+			return data, nil
+		},
+		func(ctx context.Context, data []byte) error {
+			if err := client.SetSecret(ctx, secret, base64.StdEncoding.EncodeToString(data)); err != nil {
+				return fmt.Errorf("failed to set secret %q: %v", secret, err)
+			}
+			return nil
+		},
+	)
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// Codec represents a mechanism for serializing Refreshables to bytes and back.
+type Codec[T any] interface {
+	// Encode serializes a Refreshable.
+	Encode(*refresh.Refreshable[T]) ([]byte, error)
+
+	// Decode deserializes a Refreshable.
+	Decode([]byte) (*refresh.Refreshable[T], error)
+}
+
+// TimeEncoding represents the way in which a Codec serializes timestamps.
+type TimeEncoding int
+
+const (
+	// TimeEncodingRFC3339 serializes timestamps as RFC3339 strings
+	// (with sub-second precision, if any). This is the default.
+	TimeEncodingRFC3339 TimeEncoding = iota
+
+	// TimeEncodingUnixSeconds serializes timestamps as integer seconds since the Unix epoch.
+	TimeEncodingUnixSeconds
+
+	// TimeEncodingUnixMillis serializes timestamps as integer milliseconds since the Unix epoch.
+	TimeEncodingUnixMillis
+)
+
+// encode serializes a timestamp as JSON.
+func (e TimeEncoding) encode(t time.Time) ([]byte, error) {
+	switch e {
+	case TimeEncodingUnixSeconds:
+		return json.Marshal(t.Unix())
+	case TimeEncodingUnixMillis:
+		return json.Marshal(t.UnixMilli())
+	default:
+		return json.Marshal(t.Format(time.RFC3339Nano))
+	}
+}
+
+// decode deserializes a timestamp from JSON. RFC3339 strings are
+// always accepted, such that the encoding of already stored values
+// does not need to match the current configuration.
+func (e TimeEncoding) decode(data json.RawMessage) (time.Time, error) {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		return time.Parse(time.RFC3339Nano, str)
+	}
+	var num int64
+	if err := json.Unmarshal(data, &num); err != nil {
+		return time.Time{}, fmt.Errorf("timestamp is neither a string nor an integer: %s", data)
+	}
+	if e == TimeEncodingUnixMillis {
+		return time.UnixMilli(num), nil
+	}
+	return time.Unix(num, 0), nil
+}
+
+// JSONCodecOption represents a JSON Codec configuration option.
+type JSONCodecOption func(*jsonCodecConfig)
+
+// jsonCodecConfig is the configuration of a JSON Codec.
+type jsonCodecConfig struct {
+	timeEncoding TimeEncoding
+}
+
+// WithTimeEncoding is the JSON Codec Option to set how the IssuedAt and ExpiresAt timestamps
+// are serialized, independently of the value. This is useful when stored values are shared
+// with consumers written in other languages.
+func WithTimeEncoding(timeEncoding TimeEncoding) JSONCodecOption {
+	return func(c *jsonCodecConfig) { c.timeEncoding = timeEncoding }
+}
+
+// jsonCodec is a Codec which serializes Refreshables as JSON envelopes.
+type jsonCodec[T any] struct {
+	config jsonCodecConfig
+}
+
+// jsonEnvelope is the JSON representation of a Refreshable.
+type jsonEnvelope struct {
+	Value     json.RawMessage `json:"value"`
+	IssuedAt  json.RawMessage `json:"issued_at"`
+	ExpiresAt json.RawMessage `json:"expires_at"`
+	RefreshAt json.RawMessage `json:"refresh_at,omitempty"`
+	NotBefore json.RawMessage `json:"not_before,omitempty"`
+}
+
+// NewJSONCodec returns a Codec which serializes Refreshables as JSON objects
+// with the value (encoded with encoding/json) under "value" and the timestamps
+// under "issued_at", "expires_at", and (if set) "refresh_at" and "not_before".
+func NewJSONCodec[T any](opts ...JSONCodecOption) Codec[T] {
+	c := &jsonCodec[T]{config: jsonCodecConfig{timeEncoding: TimeEncodingRFC3339}}
+	for _, opt := range opts {
+		opt(&c.config)
+	}
+	return c
+}
+
+// maxPooledBufferSize is the capacity above which buffers are not returned
+// to the pool, such that one large value does not pin memory indefinitely.
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool pools the buffers in which values are encoded before being wrapped in envelopes.
+// Only such intermediate buffers are pooled, as backends may retain the data they are given.
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// Encode serializes a Refreshable as a JSON envelope.
+func (c *jsonCodec[T]) Encode(refreshable *refresh.Refreshable[T]) ([]byte, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+	if err := json.NewEncoder(buf).Encode(refreshable.Value); err != nil {
+		return nil, fmt.Errorf("failed to encode value: %v", err)
+	}
+	value := json.RawMessage(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	issuedAt, err := c.config.timeEncoding.encode(refreshable.IssuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode issued at: %v", err)
+	}
+	expiresAt, err := c.config.timeEncoding.encode(refreshable.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode expires at: %v", err)
+	}
+	env := &jsonEnvelope{
+		Value:     value,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+	}
+	if !refreshable.RefreshAt.IsZero() {
+		if env.RefreshAt, err = c.config.timeEncoding.encode(refreshable.RefreshAt); err != nil {
+			return nil, fmt.Errorf("failed to encode refresh at: %v", err)
+		}
+	}
+	if !refreshable.NotBefore.IsZero() {
+		if env.NotBefore, err = c.config.timeEncoding.encode(refreshable.NotBefore); err != nil {
+			return nil, fmt.Errorf("failed to encode not before: %v", err)
+		}
+	}
+	return json.Marshal(env)
+}
+
+// Decode deserializes a Refreshable from a JSON envelope.
+func (c *jsonCodec[T]) Decode(data []byte) (*refresh.Refreshable[T], error) {
+	var env jsonEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("failed to decode envelope: %v", err)
+	}
+	var value T
+	if err := json.Unmarshal(env.Value, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode value: %v", err)
+	}
+	issuedAt, err := c.config.timeEncoding.decode(env.IssuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode issued at: %v", err)
+	}
+	expiresAt, err := c.config.timeEncoding.decode(env.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode expires at: %v", err)
+	}
+	var refreshAt time.Time
+	if len(env.RefreshAt) > 0 {
+		if refreshAt, err = c.config.timeEncoding.decode(env.RefreshAt); err != nil {
+			return nil, fmt.Errorf("failed to decode refresh at: %v", err)
+		}
+	}
+	var notBefore time.Time
+	if len(env.NotBefore) > 0 {
+		if notBefore, err = c.config.timeEncoding.decode(env.NotBefore); err != nil {
+			return nil, fmt.Errorf("failed to decode not before: %v", err)
+		}
+	}
+	return &refresh.Refreshable[T]{
+		Value:     value,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+		RefreshAt: refreshAt,
+		NotBefore: notBefore,
+	}, nil
+}
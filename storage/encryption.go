@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// Cipher represents an authenticated encryption scheme used to protect stored data.
+//
+// Implementations can be swapped to satisfy an organization's cryptographic policies.
+type Cipher interface {
+	// Seal encrypts and authenticates plaintext, binding the result to the given additional
+	// data (e.g. "value" or "metadata"), which is authenticated but not encrypted.
+	Seal(plaintext, additionalData []byte) ([]byte, error)
+
+	// Open decrypts and authenticates ciphertext produced by Seal with the same additional data.
+	Open(ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// The additional data with which blobs are sealed, such that a blob stored for
+// one purpose (e.g. metadata) cannot be passed off as another (e.g. a value).
+var (
+	valueAdditionalData    = []byte("refresh/storage value")
+	metadataAdditionalData = []byte("refresh/storage metadata")
+)
+
+// KDF represents a key derivation function used to derive encryption keys from secrets.
+//
+// Implementations can be swapped to satisfy an organization's cryptographic policies.
+type KDF interface {
+	// DeriveKey derives a key of the given length from a secret, salt, and context info.
+	DeriveKey(secret, salt, info []byte, length int) ([]byte, error)
+}
+
+// WithCipher is the storage Option to encrypt serialized data with the given
+// Cipher before it is written to the backend (and decrypt it after reading).
+func WithCipher[T any](c Cipher) Option[T] {
+	return func(s *storage[T]) { s.cipher = c }
+}
+
+// aesGCMCipher is a Cipher implementing AES-GCM with random nonces
+// prepended to the ciphertext.
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher returns a Cipher implementing AES-GCM with the given 16, 24, or 32 byte
+// key (for AES-128, AES-192, or AES-256 respectively).
+func NewAESGCMCipher(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize aes: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize gcm: %v", err)
+	}
+	return &aesGCMCipher{aead: aead}, nil
+}
+
+// NewAESGCMCipherFromSecret returns an AES-256-GCM Cipher keyed with
+// a key derived from the given secret and salt with the given KDF.
+func NewAESGCMCipherFromSecret(kdf KDF, secret, salt []byte) (Cipher, error) {
+	key, err := kdf.DeriveKey(secret, salt, []byte("refresh/storage aes-256-gcm"), 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+	return NewAESGCMCipher(key)
+}
+
+// Seal encrypts and authenticates plaintext, binding the result to the given additional data.
+func (c *aesGCMCipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts and authenticates ciphertext produced by Seal with the same additional data.
+func (c *aesGCMCipher) Open(ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ciphertext: %v", err)
+	}
+	return plaintext, nil
+}
+
+// hkdfKDF is a KDF implementing HKDF (RFC 5869).
+type hkdfKDF struct {
+	hash func() hash.Hash
+}
+
+// NewHKDFSHA256 returns a KDF implementing HKDF (RFC 5869) with HMAC-SHA256.
+func NewHKDFSHA256() KDF {
+	return &hkdfKDF{hash: sha256.New}
+}
+
+// DeriveKey derives a key of the given length from a secret, salt, and context info.
+func (k *hkdfKDF) DeriveKey(secret, salt, info []byte, length int) ([]byte, error) {
+	if length <= 0 || length > 255*k.hash().Size() {
+		return nil, fmt.Errorf("invalid key length %d", length)
+	}
+	key := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(k.hash, secret, salt, info), key); err != nil {
+		return nil, fmt.Errorf("failed to expand key: %v", err)
+	}
+	return key, nil
+}
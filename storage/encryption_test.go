@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestHKDFSHA256(t *testing.T) {
+	// RFC 5869, test case 1
+	key, err := NewHKDFSHA256().DeriveKey(
+		mustHex(t, "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b"),
+		mustHex(t, "000102030405060708090a0b0c"),
+		mustHex(t, "f0f1f2f3f4f5f6f7f8f9"),
+		42,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mustHex(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865")
+	if !bytes.Equal(key, want) {
+		t.Errorf("got key %x, expected %x", key, want)
+	}
+
+	for _, length := range []int{0, 255*32 + 1} {
+		if _, err := NewHKDFSHA256().DeriveKey([]byte("secret"), nil, nil, length); err == nil {
+			t.Errorf("expected an error for key length %d", length)
+		}
+	}
+}
+
+func TestAESGCMCipherAdditionalData(t *testing.T) {
+	cipher, err := NewAESGCMCipherFromSecret(NewHKDFSHA256(), []byte("secret"), []byte("salt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := cipher.Seal([]byte("plaintext"), valueAdditionalData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := cipher.Open(sealed, valueAdditionalData)
+	if err != nil || string(opened) != "plaintext" {
+		t.Fatalf("got %q (error %v), expected the plaintext", opened, err)
+	}
+	if _, err := cipher.Open(sealed, metadataAdditionalData); err == nil {
+		t.Error("expected a value blob not to open as metadata")
+	}
+}
+
+func TestStorageRejectsSwappedBlobs(t *testing.T) {
+	cipher, err := NewAESGCMCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, metadata := memoryBackend(), memoryBackend()
+	s := New(values, NewJSONCodec[string](), WithCipher[string](cipher), WithMetadataBackend[string](metadata))
+	ctx := context.Background()
+
+	now := time.Now()
+	if err := s.Put(ctx, &refresh.Refreshable[string]{Value: "value", IssuedAt: now, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.(refresh.MetadataStorage[string]).PutMetadata(ctx, &refresh.Metadata{ConsecutiveFailures: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx); err != nil {
+		t.Fatalf("failed to get value: %v", err)
+	}
+
+	// the metadata blob written in place of the value must not be accepted
+	data, err := metadata.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := values.Put(ctx, data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx); err == nil {
+		t.Error("expected the metadata blob not to be accepted as a value")
+	}
+}
@@ -0,0 +1,154 @@
+// Package etcd provides a refresh.Storage which persists refreshed values
+// under an etcd key, and a watch which notifies of values changed externally
+// (e.g. by another replica or an operator).
+//
+// A clientv3.Client needs a small adapter to satisfy Client, and to satisfy Watcher
+// by collapsing its watch responses into change signals.
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/storage"
+)
+
+// Client represents access to the keys of an etcd cluster.
+type Client interface {
+	// Get returns the value of the given key, or an
+	// error matching storage.ErrNotFound if there is none.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put sets the value of the given key.
+	Put(ctx context.Context, key string, value []byte) error
+}
+
+// Watcher represents the ability to watch etcd keys for changes.
+type Watcher interface {
+	// Watch returns a channel which receives a signal whenever the given key
+	// changes, until the given context is done or the watch otherwise ends, at
+	// which point the channel is closed.
+	Watch(ctx context.Context, key string) (<-chan struct{}, error)
+}
+
+// NotFoundError is the error returned when a key does not exist.
+type NotFoundError struct {
+	Key string
+}
+
+// Error returns the error's message.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("key %q not found", e.Key)
+}
+
+// Is returns true for storage.ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == storage.ErrNotFound
+}
+
+// Key returns the key of a value under a prefix, e.g. "/my-app/api-token".
+func Key(prefix, name string) string {
+	return path.Join("/", prefix, name)
+}
+
+// New returns a refresh.Storage which persists Refreshables serialized
+// with the given Codec under the given key (see Key).
+func New[T any](client Client, key string, codec storage.Codec[T], opts ...storage.Option[T]) refresh.Storage[T] {
+	return storage.New(NewBackend(client, key), codec, opts...)
+}
+
+// NewBackend returns a storage.Backend which stores data under the given key.
+func NewBackend(client Client, key string) storage.Backend {
+	return storage.BackendFromFunctions(
+		func(ctx context.Context) ([]byte, error) {
+			data, err := client.Get(ctx, key)
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, &NotFoundError{Key: key}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get key %q: %v", key, err)
+			}
+			return data, nil
+		},
+		func(ctx context.Context, data []byte) error {
+			if err := client.Put(ctx, key, data); err != nil {
+				return fmt.Errorf("failed to put key %q: %v", key, err)
+			}
+			return nil
+		},
+	)
+}
+
+// rewatchDelay is the delay before re-establishing a watch which ended or failed.
+const rewatchDelay = 5 * time.Second
+
+// Watch calls onChange with every value stored under the given key which differs (in its
+// timestamps) from the given Refresher's current value, i.e. with values changed externally
+// rather than by the Refresher itself, if the given Client is a Watcher. Values are read with
+// the given Storage (which must store them under the same key), such that they are decrypted
+// and decoded as when the Refresher loads them. Watches which end (as they routinely do) are
+// re-established, and deletions are ignored. With refresh.WithStoreBeforeSwap, values stored
+// by the Refresher itself may be reported if the watch beats the Refresher's swap.
+//
+// The returned function stops watching, which also happens once the Refresher stops.
+// If the Client is not a Watcher, Watch does nothing.
+func Watch[T any](
+	r refresh.Refresher[T],
+	client Client,
+	key string,
+	s refresh.Storage[T],
+	onChange func(*refresh.Refreshable[T]),
+) (stop func()) {
+	watcher, ok := client.(Watcher)
+	if !ok {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	stop = func() { once.Do(cancel) }
+
+	go func() {
+		defer stop()
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-r.Done():
+				stop()
+			}
+		}()
+
+		var last *refresh.Refreshable[T]
+		for ctx.Err() == nil {
+			changes, err := watcher.Watch(ctx, key)
+			if err == nil {
+				for range changes {
+					value, err := s.Get(ctx)
+					if err != nil || value == nil {
+						continue // e.g. deleted
+					}
+					if sameTimestamps(value, last) || sameTimestamps(value, r.GetCurrentUnsafe()) {
+						continue // already notified, or stored by the refresher itself
+					}
+					last = value
+					onChange(value)
+				}
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(rewatchDelay):
+			}
+		}
+	}()
+	return stop
+}
+
+// sameTimestamps returns true if the given Refreshables were issued and expire at the same times.
+func sameTimestamps[T any](a, b *refresh.Refreshable[T]) bool {
+	return a != nil && b != nil && a.IssuedAt.Equal(b.IssuedAt) && a.ExpiresAt.Equal(b.ExpiresAt)
+}
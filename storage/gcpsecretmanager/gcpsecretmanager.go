@@ -0,0 +1,79 @@
+// Package gcpsecretmanager provides a refresh.Storage which persists
+// refreshed values as versions of a Google Cloud Secret Manager secret.
+//
+// Versions are accessed through the Client interface, e.g. a thin wrapper around
+// a secretmanager.Client which maps NotFound errors to storage.ErrNotFound.
+//
+// Every write adds a new secret version, so consider configuring the secret
+// with a version destroy TTL or a cleanup job to bound the number of versions.
+package gcpsecretmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/storage"
+)
+
+// Client represents access to Secret Manager secrets.
+type Client interface {
+	// AccessSecretVersion returns the payload of the secret version with the given
+	// resource name, or an error matching storage.ErrNotFound if it does not exist.
+	AccessSecretVersion(ctx context.Context, name string) ([]byte, error)
+
+	// AddSecretVersion adds a version with the given payload
+	// to the secret with the given (parent) resource name.
+	AddSecretVersion(ctx context.Context, parent string, data []byte) error
+}
+
+// NotFoundError is the error returned when a secret has no accessible version.
+type NotFoundError struct {
+	Secret string
+}
+
+// Error returns the error's message.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("secret %q has no accessible version", e.Secret)
+}
+
+// Is returns true for storage.ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == storage.ErrNotFound
+}
+
+// SecretName returns the resource name of a secret, e.g. "projects/my-project/secrets/my-secret".
+func SecretName(project, secret string) string {
+	return fmt.Sprintf("projects/%s/secrets/%s", project, secret)
+}
+
+// New returns a refresh.Storage which persists Refreshables serialized with the given Codec
+// as versions of the secret with the given resource name (see SecretName). Values are read
+// from the secret's latest version.
+func New[T any](client Client, secret string, codec storage.Codec[T], opts ...storage.Option[T]) refresh.Storage[T] {
+	return storage.New(NewBackend(client, secret), codec, opts...)
+}
+
+// NewBackend returns a storage.Backend which stores data as versions
+// of the secret with the given resource name (see SecretName).
+func NewBackend(client Client, secret string) storage.Backend {
+	return storage.BackendFromFunctions(
+		func(ctx context.Context) ([]byte, error) {
+			data, err := client.AccessSecretVersion(ctx, secret+"/versions/latest")
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, &NotFoundError{Secret: secret}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to access secret %q: %v", secret, err)
+			}
+			return data, nil
+		},
+		func(ctx context.Context, data []byte) error {
+			if err := client.AddSecretVersion(ctx, secret, data); err != nil {
+				return fmt.Errorf("failed to add version to secret %q: %v", secret, err)
+			}
+			return nil
+		},
+	)
+}
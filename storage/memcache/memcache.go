@@ -0,0 +1,95 @@
+// Package memcache provides a refresh.Storage which persists
+// refreshed values as a Memcached item.
+//
+// Client matches the Get and Set methods of a gomemcache memcache.Client
+// once cache misses are mapped to storage.ErrNotFound.
+//
+// Memcached may evict items at any time (e.g. under memory pressure), in which case
+// refreshers acquire a fresh value as if nothing was ever stored. Items are limited
+// to 1MB by default.
+package memcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adrianosela/refresh"
+	"github.com/adrianosela/refresh/storage"
+)
+
+// MaxKeyLength is the maximum length of Memcached keys.
+const MaxKeyLength = 250
+
+// Client represents access to a Memcached cluster.
+type Client interface {
+	// Get returns the value of the item with the given key,
+	// or an error matching storage.ErrNotFound if there is none.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set sets the value of the item with the given key.
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// NotFoundError is the error returned when an item does not exist (or was evicted).
+type NotFoundError struct {
+	Key string
+}
+
+// Error returns the error's message.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("item %q not found", e.Key)
+}
+
+// Is returns true for storage.ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == storage.ErrNotFound
+}
+
+// Key returns the key of an item in a namespace, e.g. "my-app:api-token". Names which
+// would result in keys which are too long or contain whitespace or control characters
+// (which Memcached does not allow) are replaced by their hex encoded SHA-256 hash.
+func Key(namespace, name string) string {
+	key := namespace + ":" + name
+	if len(key) <= MaxKeyLength && !strings.ContainsFunc(key, isInvalidKeyRune) {
+		return key
+	}
+	hash := sha256.Sum256([]byte(name))
+	return namespace + ":" + hex.EncodeToString(hash[:])
+}
+
+// isInvalidKeyRune returns true for characters which Memcached keys must not contain.
+func isInvalidKeyRune(r rune) bool {
+	return r <= ' ' || r == 0x7f
+}
+
+// New returns a refresh.Storage which persists Refreshables serialized
+// with the given Codec as the item with the given key (see Key).
+func New[T any](client Client, key string, codec storage.Codec[T], opts ...storage.Option[T]) refresh.Storage[T] {
+	return storage.New(NewBackend(client, key), codec, opts...)
+}
+
+// NewBackend returns a storage.Backend which stores data as the item with the given key.
+func NewBackend(client Client, key string) storage.Backend {
+	return storage.BackendFromFunctions(
+		func(ctx context.Context) ([]byte, error) {
+			data, err := client.Get(ctx, key)
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, &NotFoundError{Key: key}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get item %q: %v", key, err)
+			}
+			return data, nil
+		},
+		func(ctx context.Context, data []byte) error {
+			if err := client.Set(ctx, key, data); err != nil {
+				return fmt.Errorf("failed to set item %q: %v", key, err)
+			}
+			return nil
+		},
+	)
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adrianosela/refresh"
+)
+
+// WithMetadataBackend is the storage Option to also persist refresher Metadata, serialized
+// as JSON (and encrypted, if a Cipher is set), in the given Backend. The resulting Storage is
+// a refresh.MetadataStorage, such that refreshers resume their state after restarts.
+func WithMetadataBackend[T any](backend Backend) Option[T] {
+	return func(s *storage[T]) { s.metadataBackend = backend }
+}
+
+// metadataStorage is a refresh.MetadataStorage which persists
+// Metadata in the metadata backend of a storage.
+type metadataStorage[T any] struct {
+	*storage[T]
+}
+
+// ensure metadataStorage implements refresh.MetadataStorage
+var _ refresh.MetadataStorage[any] = (*metadataStorage[any])(nil)
+
+// GetMetadata retrieves Metadata from the metadata backend.
+func (s *metadataStorage[T]) GetMetadata(ctx context.Context) (*refresh.Metadata, error) {
+	data, err := s.metadataBackend.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.cipher != nil {
+		if data, err = s.cipher.Open(data, metadataAdditionalData); err != nil {
+			return nil, err
+		}
+	}
+	var metadata refresh.Metadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %v", err)
+	}
+	return &metadata, nil
+}
+
+// PutMetadata stores Metadata in the metadata backend.
+func (s *metadataStorage[T]) PutMetadata(ctx context.Context, metadata *refresh.Metadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %v", err)
+	}
+	if s.cipher != nil {
+		if data, err = s.cipher.Seal(data, metadataAdditionalData); err != nil {
+			return err
+		}
+	}
+	return s.metadataBackend.Put(ctx, data)
+}
@@ -0,0 +1,185 @@
+// Package storage provides building blocks for refresh.Storage implementations
+// backed by byte-oriented stores (files, key-value stores, secret managers, etc).
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/adrianosela/refresh"
+)
+
+// ErrNotFound is matched (with errors.Is) by the errors Backends return from Get when no
+// data has been stored yet. It is refresh.ErrNotStored, such that refreshers do not retry
+// reading from empty storage.
+var ErrNotFound = refresh.ErrNotStored
+
+// Backend represents a store of serialized Refreshables.
+type Backend interface {
+	// Get retrieves serialized data, or an error matching ErrNotFound if there is none.
+	Get(context.Context) ([]byte, error)
+
+	// Put stores serialized data.
+	Put(context.Context, []byte) error
+}
+
+// backend is a Backend which runs inner
+// functions to store and retrieve serialized data.
+type backend struct {
+	getFunc func(context.Context) ([]byte, error)
+	putFunc func(context.Context, []byte) error
+}
+
+// Get retrieves serialized data by running the backend's inner getFunc.
+func (b *backend) Get(ctx context.Context) ([]byte, error) { return b.getFunc(ctx) }
+
+// Put stores serialized data by running the backend's inner putFunc.
+func (b *backend) Put(ctx context.Context, data []byte) error { return b.putFunc(ctx, data) }
+
+// BackendFromFunctions builds a functional Backend implementation.
+func BackendFromFunctions(
+	getFunc func(context.Context) ([]byte, error),
+	putFunc func(context.Context, []byte) error,
+) Backend {
+	return &backend{getFunc: getFunc, putFunc: putFunc}
+}
+
+// DecodeFunc deserializes a Refreshable.
+type DecodeFunc[T any] func([]byte) (*refresh.Refreshable[T], error)
+
+// Option represents a storage configuration option.
+type Option[T any] func(*storage[T])
+
+// WithLegacyDecoder is the storage Option to add a DecodeFunc which is tried
+// whenever the primary Codec fails to decode stored data. This allows values
+// written by a previous version of an application (e.g. before a change in the
+// shape of T) to still be used to warm-start a refresher after an upgrade.
+//
+// Legacy decoders are tried in the order in which they were added.
+func WithLegacyDecoder[T any](decode DecodeFunc[T]) Option[T] {
+	return func(s *storage[T]) { s.legacyDecoders = append(s.legacyDecoders, decode) }
+}
+
+// WithSkipUnchangedWrites is the storage Option to skip writing to the backend
+// whenever the serialized data is identical to what was last written to (or read
+// from) it. This cuts write volume for backends billed per write when upstream
+// issuers frequently return unchanged values.
+//
+// Only a hash of the serialized data is kept in memory.
+func WithSkipUnchangedWrites[T any]() Option[T] {
+	return func(s *storage[T]) { s.skipUnchangedWrites = true }
+}
+
+// storage is a refresh.Storage which serializes Refreshables
+// with a Codec and persists them in a Backend.
+type storage[T any] struct {
+	backend        Backend
+	codec          Codec[T]
+	legacyDecoders []DecodeFunc[T]
+	cipher         Cipher
+
+	metadataBackend Backend
+
+	skipUnchangedWrites bool
+	lastHashMu          sync.Mutex
+	lastHash            [sha256.Size]byte
+	hasLastHash         bool
+}
+
+// New returns a refresh.Storage which serializes Refreshables with
+// the given Codec and persists them in the given Backend.
+func New[T any](backend Backend, codec Codec[T], opts ...Option[T]) refresh.Storage[T] {
+	s := &storage[T]{backend: backend, codec: codec}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.metadataBackend != nil {
+		return &metadataStorage[T]{storage: s}
+	}
+	return s
+}
+
+// Get retrieves a Refreshable from the backend.
+func (s *storage[T]) Get(ctx context.Context) (*refresh.Refreshable[T], error) {
+	data, err := s.backend.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.cipher != nil {
+		if data, err = s.cipher.Open(data, valueAdditionalData); err != nil {
+			return nil, err
+		}
+	}
+	refreshable, err := s.decode(data)
+	if err != nil {
+		return nil, err
+	}
+	s.setLastHash(data)
+	return refreshable, nil
+}
+
+// Put stores a Refreshable in the backend.
+func (s *storage[T]) Put(ctx context.Context, refreshable *refresh.Refreshable[T]) error {
+	data, err := s.codec.Encode(refreshable)
+	if err != nil {
+		return err
+	}
+	if s.isLastHash(data) {
+		return nil
+	}
+	sealed := data
+	if s.cipher != nil {
+		if sealed, err = s.cipher.Seal(data, valueAdditionalData); err != nil {
+			return err
+		}
+	}
+	if err := s.backend.Put(ctx, sealed); err != nil {
+		return err
+	}
+	s.setLastHash(data)
+	return nil
+}
+
+// isLastHash returns true if skipping unchanged writes is enabled and
+// the given data matches what was last written to (or read from) the backend.
+func (s *storage[T]) isLastHash(data []byte) bool {
+	if !s.skipUnchangedWrites {
+		return false
+	}
+	hash := sha256.Sum256(data)
+	s.lastHashMu.Lock()
+	defer s.lastHashMu.Unlock()
+	return s.hasLastHash && s.lastHash == hash
+}
+
+// setLastHash records the hash of the data last written to (or read from) the backend.
+func (s *storage[T]) setLastHash(data []byte) {
+	if !s.skipUnchangedWrites {
+		return
+	}
+	hash := sha256.Sum256(data)
+	s.lastHashMu.Lock()
+	defer s.lastHashMu.Unlock()
+	s.lastHash, s.hasLastHash = hash, true
+}
+
+// decode deserializes a Refreshable with the primary
+// codec, falling back to any legacy decoders.
+func (s *storage[T]) decode(data []byte) (*refresh.Refreshable[T], error) {
+	refreshable, err := s.codec.Decode(data)
+	if err == nil {
+		return refreshable, nil
+	}
+	errs := []error{err}
+	for i, decode := range s.legacyDecoders {
+		refreshable, legacyErr := decode(data)
+		if legacyErr == nil {
+			return refreshable, nil
+		}
+		errs = append(errs, fmt.Errorf("legacy decoder %d: %v", i, legacyErr))
+	}
+	return nil, errors.Join(errs...)
+}
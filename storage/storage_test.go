@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// memoryBackend returns a Backend which keeps data in memory.
+func memoryBackend() Backend {
+	var stored []byte
+	return BackendFromFunctions(
+		func(context.Context) ([]byte, error) {
+			if stored == nil {
+				return nil, ErrNotFound
+			}
+			return stored, nil
+		},
+		func(_ context.Context, data []byte) error {
+			stored = data
+			return nil
+		},
+	)
+}
+
+type token struct {
+	AccessToken string   `json:"access_token"`
+	Scopes      []string `json:"scopes"`
+}
+
+func newToken() *refresh.Refreshable[token] {
+	now := time.Now()
+	return &refresh.Refreshable[token]{
+		Value:     token{AccessToken: "0123456789abcdef0123456789abcdef", Scopes: []string{"read", "write"}},
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+}
+
+func TestSkipUnchangedWrites(t *testing.T) {
+	cipher, err := NewAESGCMCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, opts := range map[string][]Option[token]{
+		"plain":     nil,
+		"encrypted": {WithCipher[token](cipher)}, // ciphertexts differ even for unchanged values
+	} {
+		t.Run(name, func(t *testing.T) {
+			backend := memoryBackend()
+			writes := 0
+			counting := BackendFromFunctions(backend.Get, func(ctx context.Context, data []byte) error {
+				writes++
+				return backend.Put(ctx, data)
+			})
+			s := New(counting, NewJSONCodec[token](), append(opts, WithSkipUnchangedWrites[token]())...)
+			ctx := context.Background()
+
+			put := func(refreshable *refresh.Refreshable[token], expectWrites int) {
+				t.Helper()
+				if err := s.Put(ctx, refreshable); err != nil {
+					t.Fatal(err)
+				}
+				if writes != expectWrites {
+					t.Errorf("%d writes, expected %d", writes, expectWrites)
+				}
+			}
+			refreshable := newToken()
+			put(refreshable, 1)
+			put(refreshable, 1) // unchanged
+
+			changedValue := *refreshable
+			changedValue.Value = token{AccessToken: "fedcba9876543210fedcba9876543210"}
+			put(&changedValue, 2)
+			put(&changedValue, 2)
+
+			changedExpiry := changedValue
+			changedExpiry.ExpiresAt = changedExpiry.ExpiresAt.Add(time.Minute)
+			put(&changedExpiry, 3)
+
+			// a new storage skips writing what it read from the backend
+			s = New(counting, NewJSONCodec[token](), append(opts, WithSkipUnchangedWrites[token]())...)
+			read, err := s.Get(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			put(read, 3)
+			put(refreshable, 4)
+		})
+	}
+
+	backend := memoryBackend()
+	writes := 0
+	s := New(BackendFromFunctions(backend.Get, func(ctx context.Context, data []byte) error {
+		writes++
+		return backend.Put(ctx, data)
+	}), NewJSONCodec[token]())
+	refreshable := newToken()
+	for i := 0; i < 2; i++ {
+		if err := s.Put(context.Background(), refreshable); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if writes != 2 {
+		t.Errorf("%d writes without WithSkipUnchangedWrites, expected 2", writes)
+	}
+}
+
+func BenchmarkCodec(b *testing.B) {
+	codec := NewJSONCodec[token]()
+	refreshable := newToken()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := codec.Encode(refreshable)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := codec.Decode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCipher(b *testing.B) {
+	cipher, err := NewAESGCMCipher(make([]byte, 32))
+	if err != nil {
+		b.Fatal(err)
+	}
+	plaintext := make([]byte, 256)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(plaintext)))
+	for i := 0; i < b.N; i++ {
+		ciphertext, err := cipher.Seal(plaintext, valueAdditionalData)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := cipher.Open(ciphertext, valueAdditionalData); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStorage(b *testing.B) {
+	cipher, err := NewAESGCMCipher(make([]byte, 32))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for name, opts := range map[string][]Option[token]{
+		"plain":     nil,
+		"encrypted": {WithCipher[token](cipher)},
+	} {
+		b.Run(name, func(b *testing.B) {
+			s := New(memoryBackend(), NewJSONCodec[token](), opts...)
+			refreshable := newToken()
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := s.Put(ctx, refreshable); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := s.Get(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package strategies
+
+import (
+	"sync"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// AdaptiveOption represents an adaptive strategy configuration option.
+type AdaptiveOption func(*adaptiveConfig)
+
+// adaptiveConfig is the configuration of an adaptive strategy.
+type adaptiveConfig struct {
+	smoothing          float64
+	durationMultiplier float64
+	failureMargin      float64
+}
+
+// WithSmoothing is the AdaptiveOption to set the weight, within (0, 1], of every new refresh
+// attempt in the moving averages of refresh duration and failure rate. Higher values adapt
+// faster to changes. The default is 0.2.
+func WithSmoothing(smoothing float64) AdaptiveOption {
+	return func(c *adaptiveConfig) { c.smoothing = clamp(smoothing, 0.01, 1) }
+}
+
+// WithDurationMultiplier is the AdaptiveOption to set how many (average) refresh durations
+// refreshes are brought forward by. The default is 3.
+func WithDurationMultiplier(multiplier float64) AdaptiveOption {
+	return func(c *adaptiveConfig) { c.durationMultiplier = multiplier }
+}
+
+// WithFailureMargin is the AdaptiveOption to set the fraction, within [0, 1], of the time between
+// a value's issuance and its target refresh time by which refreshes are brought forward when all
+// recent refresh attempts failed (and proportionally less for lower failure rates). The default is 0.5.
+func WithFailureMargin(margin float64) AdaptiveOption {
+	return func(c *adaptiveConfig) { c.failureMargin = clamp(margin, 0, 1) }
+}
+
+type strategyAdaptive[T any] struct {
+	inner  refresh.RefreshStrategy[T]
+	config adaptiveConfig
+
+	mu          sync.Mutex
+	avgDuration float64 // exponentially weighted moving average, in seconds
+	failureRate float64 // exponentially weighted moving average, in [0, 1]
+	observed    bool
+}
+
+// NewAdaptive returns a refresh.FeedbackStrategy which will return the refresh time returned by
+// the given strategy, brought forward by a safety margin informed by recent refresh attempts: a
+// multiple of the (moving) average refresh duration, plus a fraction of the value's lifetime
+// proportional to the (moving) refresh failure rate. Slow or flaky upstreams thus get refreshed
+// earlier, leaving more time for retries before values expire. Refresh times are never in the past.
+func NewAdaptive[T any](inner refresh.RefreshStrategy[T], opts ...AdaptiveOption) refresh.FeedbackStrategy[T] {
+	s := &strategyAdaptive[T]{
+		inner: inner,
+		config: adaptiveConfig{
+			smoothing:          0.2,
+			durationMultiplier: 3,
+			failureMargin:      0.5,
+		},
+	}
+	for _, opt := range opts {
+		opt(&s.config)
+	}
+	return s
+}
+
+// RecordRefresh records the duration and the error (nil if successful) of a refresh attempt.
+func (s *strategyAdaptive[T]) RecordRefresh(duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	if !s.observed {
+		s.avgDuration, s.failureRate, s.observed = duration.Seconds(), failed, true
+		return
+	}
+	alpha := s.config.smoothing
+	s.avgDuration = alpha*duration.Seconds() + (1-alpha)*s.avgDuration
+	s.failureRate = alpha*failed + (1-alpha)*s.failureRate
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyAdaptive[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	s.mu.Lock()
+	avgDuration, failureRate := s.avgDuration, s.failureRate
+	s.mu.Unlock()
+
+	now := time.Now()
+	target := s.inner.GetRefreshAt(refreshable)
+
+	margin := time.Duration(s.config.durationMultiplier * avgDuration * float64(time.Second))
+	if window := target.Sub(refreshable.IssuedAt); window > 0 {
+		margin += time.Duration(failureRate * s.config.failureMargin * float64(window))
+	}
+
+	refreshAt := target.Add(-margin)
+	if refreshAt.Before(now) {
+		return now
+	}
+	return refreshAt
+}
@@ -0,0 +1,86 @@
+package strategies
+
+import (
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+type strategyEarliest[T any] struct {
+	strategies []refresh.RefreshStrategy[T]
+}
+
+// Earliest returns a refresh.RefreshStrategy which will return the earliest of the refresh
+// times returned by the given strategies, e.g. "at two thirds of the lifetime, but no later
+// than the nightly maintenance window". With no strategies, it refreshes immediately.
+func Earliest[T any](strategies ...refresh.RefreshStrategy[T]) refresh.RefreshStrategy[T] {
+	return &strategyEarliest[T]{strategies: strategies}
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyEarliest[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	if len(s.strategies) == 0 {
+		return time.Now()
+	}
+	earliest := s.strategies[0].GetRefreshAt(refreshable)
+	for _, strategy := range s.strategies[1:] {
+		if t := strategy.GetRefreshAt(refreshable); t.Before(earliest) {
+			earliest = t
+		}
+	}
+	return earliest
+}
+
+type strategyLatest[T any] struct {
+	strategies []refresh.RefreshStrategy[T]
+}
+
+// Latest returns a refresh.RefreshStrategy which will return the latest of the refresh
+// times returned by the given strategies. With no strategies, it refreshes immediately.
+func Latest[T any](strategies ...refresh.RefreshStrategy[T]) refresh.RefreshStrategy[T] {
+	return &strategyLatest[T]{strategies: strategies}
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyLatest[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	if len(s.strategies) == 0 {
+		return time.Now()
+	}
+	latest := s.strategies[0].GetRefreshAt(refreshable)
+	for _, strategy := range s.strategies[1:] {
+		if t := strategy.GetRefreshAt(refreshable); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+type strategyClamped[T any] struct {
+	inner refresh.RefreshStrategy[T]
+	min   time.Duration
+	max   time.Duration
+}
+
+// Clamp returns a refresh.RefreshStrategy which will return the refresh time returned by the
+// given strategy, clamped to be no sooner than min and no later than max from now. A zero (or
+// negative) min or max leaves the respective bound unset. If min > max, max takes precedence.
+func Clamp[T any](inner refresh.RefreshStrategy[T], min, max time.Duration) refresh.RefreshStrategy[T] {
+	return &strategyClamped[T]{inner: inner, min: min, max: max}
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyClamped[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	now := time.Now()
+	refreshAt := s.inner.GetRefreshAt(refreshable)
+	if s.min > 0 {
+		if earliest := now.Add(s.min); refreshAt.Before(earliest) {
+			refreshAt = earliest
+		}
+	}
+	if s.max > 0 {
+		if latest := now.Add(s.max); refreshAt.After(latest) {
+			refreshAt = latest
+		}
+	}
+	return refreshAt
+}
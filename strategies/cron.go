@@ -0,0 +1,248 @@
+package strategies
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// cronField is the set of values allowed for a field of a cron expression, as a bitmask.
+type cronField uint64
+
+// cronFieldBounds are the minimum and maximum values of a field, and its names (if any).
+type cronFieldBounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinutes = cronFieldBounds{min: 0, max: 59}
+	cronHours   = cronFieldBounds{min: 0, max: 23}
+	cronDays    = cronFieldBounds{min: 1, max: 31}
+	cronMonths  = cronFieldBounds{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronWeekdays = cronFieldBounds{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// cronSearchLimit bounds the search for the next time matching an expression
+// which never matches, e.g. "0 0 30 2 *" (February 30th).
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+type strategyCron[T any] struct {
+	minutes, hours, days, months, weekdays cronField
+	daysRestricted, weekdaysRestricted     bool
+	location                               *time.Location
+}
+
+// NewCron returns a refresh.RefreshStrategy which will return the next time matching the given
+// cron expression, regardless of the Refreshable's IssuedAt and ExpiresAt. This is useful for
+// values rotated on calendar schedules, e.g. nightly key rotations.
+//
+// Expressions have the standard five fields (minute, hour, day of month, month, and day of week),
+// each a "*" or a comma-separated list of values, ranges ("1-5"), and steps ("*/15", "0-30/10").
+// Months and days of the week may also be given by their (three letter) English names. As in
+// most cron implementations, if both the day of month and the day of week are restricted, times
+// matching either of them match. The descriptors "@yearly", "@annually", "@monthly", "@weekly",
+// "@daily", "@midnight", and "@hourly" are also accepted.
+//
+// Times are evaluated in the local time zone, unless the expression is prefixed with a time zone
+// as in "CRON_TZ=Europe/Berlin 0 9 * * MON-FRI". Around daylight saving time transitions, times
+// which do not exist are skipped, and times which occur twice match only once (the first time).
+// If no time matches within five years, the strategy never refreshes.
+func NewCron[T any](expr string) (refresh.RefreshStrategy[T], error) {
+	s := &strategyCron[T]{location: time.Local}
+
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		tz, rest, _ := strings.Cut(expr, " ")
+		_, name, _ := strings.Cut(tz, "=")
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %v", name, err)
+		}
+		s.location = location
+		expr = strings.TrimSpace(rest)
+	}
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields but got %d", expr, len(fields))
+	}
+	var err error
+	if s.minutes, err = parseCronField(fields[0], cronMinutes); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %v", err)
+	}
+	if s.hours, err = parseCronField(fields[1], cronHours); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %v", err)
+	}
+	if s.days, err = parseCronField(fields[2], cronDays); err != nil {
+		return nil, fmt.Errorf("invalid day of month field: %v", err)
+	}
+	if s.months, err = parseCronField(fields[3], cronMonths); err != nil {
+		return nil, fmt.Errorf("invalid month field: %v", err)
+	}
+	if s.weekdays, err = parseCronField(fields[4], cronWeekdays); err != nil {
+		return nil, fmt.Errorf("invalid day of week field: %v", err)
+	}
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1 << 0 // both 0 and 7 are Sunday
+	}
+	s.daysRestricted = !strings.HasPrefix(fields[2], "*")
+	s.weekdaysRestricted = !strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// MustNewCron is like NewCron but panics if the expression is invalid.
+func MustNewCron[T any](expr string) refresh.RefreshStrategy[T] {
+	s, err := NewCron[T](expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// parseCronField parses a field of a cron expression.
+func parseCronField(field string, bounds cronFieldBounds) (cronField, error) {
+	var set cronField
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = bounds.min, bounds.max
+		case strings.Contains(rng, "-"):
+			loStr, hiStr, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseCronValue(loStr, bounds); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(hiStr, bounds); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			var err error
+			if lo, err = parseCronValue(rng, bounds); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if hasStep {
+				hi = bounds.max // "5/15" means "5-max/15"
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// parseCronValue parses a single value (a number or a name) of a cron expression field.
+func parseCronValue(str string, bounds cronFieldBounds) (int, error) {
+	if v, ok := bounds.names[strings.ToLower(str)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", str)
+	}
+	if v < bounds.min || v > bounds.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, bounds.min, bounds.max)
+	}
+	return v, nil
+}
+
+// has returns true if the given value is in the set.
+func (f cronField) has(v int) bool {
+	return f&(1<<uint(v)) != 0
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyCron[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	return s.next(time.Now())
+}
+
+// next returns the first time strictly after the given time which matches the expression.
+func (s *strategyCron[T]) next(after time.Time) time.Time {
+	after = after.In(s.location)
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if !s.months.has(int(t.Month())) {
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location))
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location))
+			continue
+		}
+		if !s.hours.has(t.Hour()) {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location))
+			continue
+		}
+		if !s.minutes.has(t.Minute()) || !wallClock(t).After(wallClock(after)) {
+			t = t.Add(time.Minute) // the latter when the clock was set back, e.g. when DST ends
+			continue
+		}
+		return t
+	}
+	// the expression never matches... never refresh again
+	return maxTime
+}
+
+// advance returns the next time to consider in the search for a matching time after t. Times
+// which do not exist (e.g. 02:00 when DST starts) are resolved by time.Date to a time which
+// may not be after t, in which case the search moves on by an hour such that it progresses.
+func advance(t, next time.Time) time.Time {
+	for !next.After(t) {
+		next = next.Add(time.Hour)
+	}
+	return next
+}
+
+// wallClock returns the given time's wall clock reading, regardless of its zone offset.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// matchesDay returns true if the day of the given time matches the expression.
+func (s *strategyCron[T]) matchesDay(t time.Time) bool {
+	day := s.days.has(t.Day())
+	weekday := s.weekdays.has(int(t.Weekday()))
+	if s.daysRestricted && s.weekdaysRestricted {
+		return day || weekday
+	}
+	return day && weekday
+}
@@ -0,0 +1,126 @@
+package strategies
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, newYork)
+	}
+	// 2024-01-01 is a Monday
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{name: "hourly", expr: "@hourly", after: at(2024, 1, 1, 10, 15), want: at(2024, 1, 1, 11, 0)},
+		{name: "daily", expr: "@daily", after: at(2024, 1, 1, 10, 15), want: at(2024, 1, 2, 0, 0)},
+		{name: "midnight", expr: "@midnight", after: at(2024, 1, 1, 10, 15), want: at(2024, 1, 2, 0, 0)},
+		{name: "weekly", expr: "@weekly", after: at(2024, 1, 1, 10, 15), want: at(2024, 1, 7, 0, 0)},
+		{name: "monthly", expr: "@monthly", after: at(2024, 1, 1, 10, 15), want: at(2024, 2, 1, 0, 0)},
+		{name: "yearly", expr: "@yearly", after: at(2024, 1, 1, 10, 15), want: at(2025, 1, 1, 0, 0)},
+		{name: "annually", expr: "@Annually", after: at(2024, 1, 1, 10, 15), want: at(2025, 1, 1, 0, 0)},
+
+		{name: "weekday names range", expr: "0 9 * * MON-FRI", after: at(2024, 1, 5, 10, 0), want: at(2024, 1, 8, 9, 0)},
+		{name: "seven is sunday", expr: "0 9 * * 7", after: at(2024, 1, 1, 10, 0), want: at(2024, 1, 7, 9, 0)},
+		{name: "zero is sunday", expr: "0 9 * * 0", after: at(2024, 1, 1, 10, 0), want: at(2024, 1, 7, 9, 0)},
+		{name: "range to seven", expr: "0 9 * * 5-7", after: at(2024, 1, 1, 10, 0), want: at(2024, 1, 5, 9, 0)},
+		{name: "range to seven includes sunday", expr: "0 9 * * 5-7", after: at(2024, 1, 6, 10, 0), want: at(2024, 1, 7, 9, 0)},
+		{name: "month names list", expr: "0 0 1 jan,JUL *", after: at(2024, 2, 1, 0, 0), want: at(2024, 7, 1, 0, 0)},
+		{name: "list of values", expr: "0 6,18 * * *", after: at(2024, 1, 1, 7, 0), want: at(2024, 1, 1, 18, 0)},
+
+		{name: "step", expr: "*/15 * * * *", after: at(2024, 1, 1, 10, 16), want: at(2024, 1, 1, 10, 30)},
+		{name: "step from value", expr: "5/15 * * * *", after: at(2024, 1, 1, 10, 21), want: at(2024, 1, 1, 10, 35)},
+		{name: "step from value wraps", expr: "5/15 * * * *", after: at(2024, 1, 1, 10, 50), want: at(2024, 1, 1, 11, 5)},
+		{name: "step within range", expr: "0-30/10 * * * *", after: at(2024, 1, 1, 10, 5), want: at(2024, 1, 1, 10, 10)},
+		{name: "step within range wraps", expr: "0-30/10 * * * *", after: at(2024, 1, 1, 10, 31), want: at(2024, 1, 1, 11, 0)},
+		{name: "strictly after", expr: "*/15 * * * *", after: at(2024, 1, 1, 10, 15), want: at(2024, 1, 1, 10, 30)},
+
+		// 2024-01-12 is a Friday, and 2024-01-13 a Saturday
+		{name: "day of month or week", expr: "0 0 13 * FRI", after: at(2024, 1, 6, 0, 0), want: at(2024, 1, 12, 0, 0)},
+		{name: "day of month or week (day)", expr: "0 0 13 * FRI", after: at(2024, 1, 12, 0, 0), want: at(2024, 1, 13, 0, 0)},
+		{name: "day of month only", expr: "0 0 13 * *", after: at(2024, 1, 6, 0, 0), want: at(2024, 1, 13, 0, 0)},
+		{name: "day of week only", expr: "0 0 * * FRI", after: at(2024, 1, 6, 0, 0), want: at(2024, 1, 12, 0, 0)},
+		{name: "leap day", expr: "0 0 29 2 *", after: at(2024, 3, 1, 0, 0), want: at(2028, 2, 29, 0, 0)},
+
+		// clocks go from 02:00 to 03:00 on 2024-03-10, and from 02:00 back to 01:00 on 2024-11-03
+		{name: "dst gap is skipped", expr: "30 2 * * *", after: at(2024, 3, 9, 3, 0), want: at(2024, 3, 11, 2, 30)},
+		{name: "dst gap neighbours", expr: "0 * * * *", after: at(2024, 3, 10, 1, 30), want: at(2024, 3, 10, 3, 0)},
+		{name: "dst overlap first", expr: "30 1 * * *", after: at(2024, 11, 3, 0, 0), want: time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC)},
+		{name: "dst overlap once", expr: "30 1 * * *", after: time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC), want: at(2024, 11, 4, 1, 30)},
+		{name: "dst overlap steps", expr: "*/30 * * * *", after: time.Date(2024, 11, 3, 5, 45, 0, 0, time.UTC), want: time.Date(2024, 11, 3, 7, 0, 0, 0, time.UTC)},
+
+		{name: "never", expr: "0 0 30 2 *", after: at(2024, 1, 1, 0, 0), want: maxTime},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := NewCron[string]("CRON_TZ=America/New_York " + test.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.(*strategyCron[string]).next(test.after); !got.Equal(test.want) {
+				t.Errorf("next(%s) = %s, expected %s", test.after, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCronTimeZone(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "CRON_TZ=Asia/Tokyo 0 9 * * *", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, // 09:00 JST
+		{expr: "TZ=UTC 0 9 * * *", want: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{expr: "  CRON_TZ=UTC   @daily ", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		s, err := NewCron[string](test.expr)
+		if err != nil {
+			t.Skipf("%q: %v", test.expr, err) // e.g. no time zone database
+		}
+		if got := s.(*strategyCron[string]).next(after); !got.Equal(test.want) {
+			t.Errorf("%q: next(%s) = %s, expected %s", test.expr, after, got, test.want)
+		}
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@reboot",
+		"CRON_TZ=Nowhere/Special 0 0 * * *",
+		"60 * * * *",
+		"-1 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"* * * foo *",
+		"* * * * sunday",
+		"*/0 * * * *",
+		"*/-5 * * * *",
+		"*/x * * * *",
+		"30-10 * * * *",
+		"1-60 * * * *",
+		"1,,2 * * * *",
+		"1- * * * *",
+		"a-b * * * *",
+	} {
+		if _, err := NewCron[string](expr); err == nil {
+			t.Errorf("%q was accepted", expr)
+		}
+	}
+}
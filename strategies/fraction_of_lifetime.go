@@ -0,0 +1,49 @@
+package strategies
+
+import (
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+type strategyFractionOfLifetime[T any] struct {
+	fraction float64
+	minLead  time.Duration
+	maxEarly time.Duration
+}
+
+// NewFractionOfLifetime returns a refresh.RefreshStrategy which will return a refresh time
+// representing the given fraction (in [0, 1]) of the refresher's lifetime (e.g. 2.0/3 for the
+// default strategy's two thirds), bounded such that it is:
+//
+//   - no later than minLead before expiry, leaving time to retry failed refreshes
+//   - no earlier than maxEarly before expiry, such that long-lived values are not refreshed
+//     needlessly often
+//
+// A zero minLead or maxEarly disables the respective bound. Should the bounds conflict,
+// minLead takes precedence. Refresh times in the past are returned as now.
+func NewFractionOfLifetime[T any](fraction float64, minLead, maxEarly time.Duration) refresh.RefreshStrategy[T] {
+	return &strategyFractionOfLifetime[T]{fraction: fraction, minLead: minLead, maxEarly: maxEarly}
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyFractionOfLifetime[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	lifetime := refreshable.ExpiresAt.Sub(refreshable.IssuedAt)
+	refreshAt := refreshable.IssuedAt.Add(time.Duration(float64(lifetime) * s.fraction))
+
+	if s.maxEarly > 0 {
+		if earliest := refreshable.ExpiresAt.Add(-s.maxEarly); refreshAt.Before(earliest) {
+			refreshAt = earliest
+		}
+	}
+	if s.minLead > 0 {
+		if latest := refreshable.ExpiresAt.Add(-s.minLead); refreshAt.After(latest) {
+			refreshAt = latest
+		}
+	}
+
+	if now := time.Now(); refreshAt.Before(now) {
+		return now
+	}
+	return refreshAt
+}
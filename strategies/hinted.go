@@ -0,0 +1,29 @@
+package strategies
+
+import (
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+type strategyHinted[T any] struct {
+	inner refresh.RefreshStrategy[T]
+}
+
+// NewHinted returns a refresh.RefreshStrategy which will return the refresh time returned by the
+// given strategy, unless the Refreshable carries a NotBefore hint later than it, in which case the
+// hint is returned instead. This keeps refreshers from hitting rate limited issuers before they
+// said new values will be available. (Refreshables with a RefreshAt are refreshed at that time
+// without consulting the strategy at all.)
+func NewHinted[T any](inner refresh.RefreshStrategy[T]) refresh.RefreshStrategy[T] {
+	return &strategyHinted[T]{inner: inner}
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyHinted[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	refreshAt := s.inner.GetRefreshAt(refreshable)
+	if refreshable.NotBefore.After(refreshAt) {
+		return refreshable.NotBefore
+	}
+	return refreshAt
+}
@@ -0,0 +1,112 @@
+package strategies
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// IntervalOption represents an interval strategy configuration option.
+type IntervalOption func(*intervalConfig)
+
+// intervalConfig is the configuration of an interval strategy.
+type intervalConfig struct {
+	anchor   time.Time
+	anchored bool
+	jitter   time.Duration
+}
+
+// WithAnchor is the IntervalOption to align refreshes to the given time, such that they happen
+// at anchor + n*every (for the smallest n resulting in a time in the future), e.g. on the hour
+// for an anchor at midnight and an interval of one hour, rather than every interval from the
+// time of the last refresh.
+func WithAnchor(anchor time.Time) IntervalOption {
+	return func(c *intervalConfig) {
+		c.anchor = anchor
+		c.anchored = true
+	}
+}
+
+// WithJitter is the IntervalOption to delay every refresh by a random duration in [0, jitter),
+// which spreads out the refreshes of many refreshers on the same interval.
+func WithJitter(jitter time.Duration) IntervalOption {
+	return func(c *intervalConfig) { c.jitter = jitter }
+}
+
+type strategyInterval[T any] struct {
+	every  time.Duration
+	config intervalConfig
+}
+
+// NewInterval returns a refresh.RefreshStrategy which will return a refresh time recurring on
+// the given interval (from the time of each refresh, or aligned to an anchor with WithAnchor),
+// regardless of the Refreshable's IssuedAt and ExpiresAt. Unlike NewScheduled, it never runs
+// out of refresh times. A non-positive interval refreshes immediately.
+func NewInterval[T any](every time.Duration, opts ...IntervalOption) refresh.RefreshStrategy[T] {
+	s := &strategyInterval[T]{every: every}
+	for _, opt := range opts {
+		opt(&s.config)
+	}
+	return s
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyInterval[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	now := time.Now()
+	if s.every <= 0 {
+		return now
+	}
+
+	next := now.Add(s.every)
+	if s.config.anchored {
+		next = nextOccurrence(s.config.anchor, s.every, now)
+	}
+	if s.config.jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(s.config.jitter))))
+	}
+	return next
+}
+
+type strategyRepeatingScheduled[T any] struct {
+	period time.Duration
+	times  []time.Time
+}
+
+// NewRepeatingScheduled returns a refresh.RefreshStrategy which, like NewScheduled, will return
+// the closest time in the future out of a given list of timestamps, except that every timestamp
+// repeats with the given period (e.g. 24 hours for the same times every day), such that it never
+// runs out of refresh times. A non-positive period behaves exactly like NewScheduled.
+func NewRepeatingScheduled[T any](period time.Duration, times ...time.Time) refresh.RefreshStrategy[T] {
+	if period <= 0 {
+		return NewScheduled[T](times...)
+	}
+	return &strategyRepeatingScheduled[T]{period: period, times: times}
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyRepeatingScheduled[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	now := time.Now()
+	var closest time.Time
+	for _, t := range s.times {
+		if next := nextOccurrence(t, s.period, now); closest.IsZero() || next.Before(closest) {
+			closest = next
+		}
+	}
+	if closest.IsZero() {
+		// no refresh times were given... never refresh
+		return maxTime
+	}
+	return closest
+}
+
+// nextOccurrence returns the first time strictly after now which is
+// the given anchor plus a (possibly negative) multiple of the period.
+func nextOccurrence(anchor time.Time, period time.Duration, now time.Time) time.Time {
+	elapsed := now.Sub(anchor)
+	periods := elapsed / period
+	if elapsed < 0 && elapsed%period != 0 {
+		periods-- // round towards negative infinity
+	}
+	return anchor.Add((periods + 1) * period)
+}
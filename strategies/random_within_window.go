@@ -1,15 +1,46 @@
 package strategies
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/adrianosela/refresh"
 )
 
+// RandomOption represents a random strategy configuration option.
+type RandomOption func(*randomConfig)
+
+// randomConfig is the configuration of a random strategy.
+type randomConfig struct {
+	float64 func() float64
+}
+
+// WithRand is the RandomOption to draw random numbers from the given *rand.Rand rather than
+// from the global source of math/rand, e.g. a seeded one for reproducible tests. Access to
+// the *rand.Rand is synchronized, such that it may not be shared with other users.
+func WithRand(rnd *rand.Rand) RandomOption {
+	var mu sync.Mutex
+	return WithRandomSource(func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return rnd.Float64()
+	})
+}
+
+// WithRandomSource is the RandomOption to draw random numbers in [0, 1) from the given
+// function rather than from the global source of math/rand. The function must be safe
+// for concurrent use.
+func WithRandomSource(float64 func() float64) RandomOption {
+	return func(c *randomConfig) { c.float64 = float64 }
+}
+
 type strategyRandomWithinLifetimeWindow[T any] struct {
-	min float64
-	max float64
+	min    float64
+	max    float64
+	config randomConfig
 }
 
 // NewRandomWithinLifetimeWindow returns a refresh.RefreshStrategy which will return a refresh time
@@ -21,17 +52,34 @@ type strategyRandomWithinLifetimeWindow[T any] struct {
 //
 // The randomness is useful as it serves as jitter to prevent en-masse Refreshable refreshes.
 //
+// Random numbers are drawn from the global source of math/rand, unless a RandomOption says otherwise.
+//
 // It is required that both min and max are within [0.01, 0.99], and that min <= max.
 // - If min or max are < 0.01, they will be overridden to 0.01
 // - If min or max are > 0.99, they will be overridden to 0.99
 // - If min > max, it will be overridden to max
-func NewRandomWithinLifetimeWindow[T any](min, max float64) refresh.RefreshStrategy[T] {
+func NewRandomWithinLifetimeWindow[T any](min, max float64, opts ...RandomOption) refresh.RefreshStrategy[T] {
 	min = clamp(min, 0.01, 0.99)
 	max = clamp(max, 0.01, 0.99)
 	if min > max {
 		min = max
 	}
-	return &strategyRandomWithinLifetimeWindow[T]{min: min, max: max}
+	s := &strategyRandomWithinLifetimeWindow[T]{min: min, max: max, config: randomConfig{float64: rand.Float64}}
+	for _, opt := range opts {
+		opt(&s.config)
+	}
+	return s
+}
+
+// NewStableWithinLifetimeWindow returns a refresh.RefreshStrategy like NewRandomWithinLifetimeWindow,
+// except that the point within the window is derived from (a hash of) the given key rather than drawn
+// at random. With a key which is stable for a given replica but different across replicas, such as
+// its hostname or pod name, every replica consistently refreshes at its own slot within the window,
+// spreading the refreshes of a fleet while keeping each replica's schedule predictable.
+func NewStableWithinLifetimeWindow[T any](min, max float64, key string) refresh.RefreshStrategy[T] {
+	sum := sha256.Sum256([]byte(key))
+	fraction := float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53) // uniformly distributed in [0, 1)
+	return NewRandomWithinLifetimeWindow[T](min, max, WithRandomSource(func() float64 { return fraction }))
 }
 
 func clamp(value, lowerBound, upperBound float64) float64 {
@@ -56,7 +104,7 @@ func (s *strategyRandomWithinLifetimeWindow[T]) GetRefreshAt(refreshable *refres
 
 	lifetimeSoFarSeconds := now.Sub(refreshable.IssuedAt).Seconds()
 	lifetimeTotalSeconds := refreshable.ExpiresAt.Sub(refreshable.IssuedAt).Seconds()
-	randomFactorInWindow := s.min + rand.Float64()*(s.max-s.min)
+	randomFactorInWindow := s.min + s.config.float64()*(s.max-s.min)
 	desiredElapsedLifetimeSeconds := lifetimeTotalSeconds * randomFactorInWindow
 
 	// already exceeded desired elapsed lifetime, refresh now
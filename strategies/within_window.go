@@ -0,0 +1,81 @@
+package strategies
+
+import (
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// maxWindowOccurrences bounds the number of window occurrences
+// considered when looking for a permitted refresh time.
+const maxWindowOccurrences = 1000
+
+type strategyWithinWindow[T any] struct {
+	inner   refresh.RefreshStrategy[T]
+	windows []refresh.TimeWindow
+}
+
+// NewWithinWindow returns a refresh.RefreshStrategy which will shift the refresh time returned by
+// the given strategy into the given (permitted) time windows, e.g. only 02:00-05:00 local time:
+//
+//	strategies.NewWithinWindow(inner, refresh.NewDailyTimeWindow(2*time.Hour, 5*time.Hour, time.Local))
+//
+// A refresh time outside of all windows is deferred to the start of the next window occurrence.
+// If that would be past the value's expiry (or there is none), the refresh is instead brought
+// forward to the start of the last window occurrence before the proposed time (or to now, if
+// within a window). If no window occurs before the value expires, the proposed time is returned
+// as is.
+//
+// Unlike refresh.WithBlackoutWindows, which lists when not to refresh, this lists when to.
+func NewWithinWindow[T any](inner refresh.RefreshStrategy[T], windows ...refresh.TimeWindow) refresh.RefreshStrategy[T] {
+	return &strategyWithinWindow[T]{inner: inner, windows: windows}
+}
+
+// GetRefreshAt returns the next refresh time for the Refreshable.
+func (s *strategyWithinWindow[T]) GetRefreshAt(refreshable *refresh.Refreshable[T]) time.Time {
+	proposed := s.inner.GetRefreshAt(refreshable)
+
+	start, _, ok := s.next(proposed)
+	if ok && !start.After(proposed) {
+		return proposed // within a window
+	}
+	if ok && start.Before(refreshable.ExpiresAt) {
+		return start
+	}
+
+	// deferring would violate expiry, so find the last occurrence before the proposed time
+	latest, found := time.Time{}, false
+	cursor := time.Now()
+	for i := 0; i < maxWindowOccurrences && cursor.Before(proposed); i++ {
+		start, end, ok := s.next(cursor)
+		if !ok || !start.Before(proposed) {
+			break
+		}
+		latest, found = start, true
+		cursor = end
+	}
+	if !found {
+		return proposed
+	}
+	return latest
+}
+
+// next returns the earliest occurrence of any of the windows which contains
+// (or, if none does, is next after) the given time.
+func (s *strategyWithinWindow[T]) next(t time.Time) (time.Time, time.Time, bool) {
+	var earliestStart, earliestEnd time.Time
+	found := false
+	for _, window := range s.windows {
+		start, end, ok := window.Next(t)
+		if !ok {
+			continue
+		}
+		if start.Before(t) {
+			start = t // ongoing occurrence
+		}
+		if !found || start.Before(earliestStart) || (start.Equal(earliestStart) && end.After(earliestEnd)) {
+			earliestStart, earliestEnd, found = start, end, true
+		}
+	}
+	return earliestStart, earliestEnd, found
+}
@@ -29,3 +29,20 @@ func (rs *refreshStrategy[T]) GetRefreshAt(refreshable *Refreshable[T]) time.Tim
 func RefreshStrategyFromFunction[T any](refreshAtFunc RefreshAtFunc[T]) RefreshStrategy[T] {
 	return &refreshStrategy[T]{refreshAtFunc: refreshAtFunc}
 }
+
+// DefaultRefreshStrategy returns the RefreshStrategy used by refreshers unless
+// otherwise specified, which refreshes values at two thirds of their lifetime.
+func DefaultRefreshStrategy[T any]() RefreshStrategy[T] {
+	return RefreshStrategyFromFunction(defaultRefreshStrategyFunc[T])
+}
+
+// FeedbackStrategy is a RefreshStrategy which is informed of the outcome of every refresh
+// attempt, e.g. to schedule refreshes earlier when refreshes are slow or flaky. A refresher
+// with a FeedbackStrategy calls RecordRefresh for every attempt before calling GetRefreshAt
+// for the value it acquired, if any. Calls may be concurrent with calls to GetRefreshAt.
+type FeedbackStrategy[T any] interface {
+	RefreshStrategy[T]
+
+	// RecordRefresh records the duration and the error (nil if successful) of a refresh attempt.
+	RecordRefresh(duration time.Duration, err error)
+}
@@ -0,0 +1,36 @@
+package refresh
+
+// Subscribe registers a function which is called with every new value of the refresher.
+func (r *refresher[T]) Subscribe(fn func(*Refreshable[T])) (unsubscribe func()) {
+	r.subscribersMu.Lock()
+	defer r.subscribersMu.Unlock()
+
+	if r.subscribers == nil {
+		r.subscribers = make(map[uint64]func(*Refreshable[T]))
+	}
+	id := r.nextSubscriberID
+	r.nextSubscriberID++
+	r.subscribers[id] = fn
+
+	return func() {
+		r.subscribersMu.Lock()
+		defer r.subscribersMu.Unlock()
+
+		delete(r.subscribers, id)
+	}
+}
+
+// notifySubscribers calls every subscriber with a new value. It must
+// only be called from the refresher's own (tracked) go-routines.
+func (r *refresher[T]) notifySubscribers(newValue *Refreshable[T]) {
+	r.subscribersMu.Lock()
+	subscribers := make([]func(*Refreshable[T]), 0, len(r.subscribers))
+	for _, fn := range r.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	r.subscribersMu.Unlock()
+
+	for _, fn := range subscribers {
+		r.emit(func() { fn(newValue) })
+	}
+}
@@ -0,0 +1,89 @@
+package refresh
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SwapOption represents a configuration option for SetRefreshFunc and SetRefreshStrategy.
+type SwapOption func(*SwapConfig)
+
+// SwapConfig is the configuration of a call to SetRefreshFunc or SetRefreshStrategy,
+// exported for the benefit of other Refresher implementations (see NewSwapConfig).
+type SwapConfig struct {
+	// RefreshImmediately is whether to refresh right away, rather than at the next scheduled time.
+	RefreshImmediately bool
+}
+
+// WithImmediateRefresh is the SwapOption to trigger a refresh right away, which is carried out
+// in the background (after any refresh already in progress) using the new RefreshFunc or
+// RefreshStrategy. Its outcome is reported to the refresher's event handlers as usual.
+func WithImmediateRefresh() SwapOption {
+	return func(c *SwapConfig) { c.RefreshImmediately = true }
+}
+
+// NewSwapConfig returns the SwapConfig resulting from applying the given SwapOption(s).
+func NewSwapConfig(opts ...SwapOption) SwapConfig {
+	var c SwapConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// SetRefreshFunc replaces the refresher's RefreshFunc.
+func (r *refresher[T]) SetRefreshFunc(refreshFunc RefreshFunc[T], opts ...SwapOption) {
+	var withPrevious RefreshFuncWithPrevious[T] = func(ctx context.Context, _ *Refreshable[T]) (*Refreshable[T], error) {
+		return refreshFunc(ctx)
+	}
+	r.swappableRefreshFunc.Store(&withPrevious)
+	r.log(slog.LevelInfo, "refresh function replaced")
+	r.afterSwap(NewSwapConfig(opts...))
+}
+
+// SetRefreshStrategy replaces the refresher's RefreshStrategy.
+func (r *refresher[T]) SetRefreshStrategy(refreshStrategy RefreshStrategy[T], opts ...SwapOption) {
+	r.swappableStrategy.Store(&refreshStrategy)
+	r.log(slog.LevelInfo, "refresh strategy replaced")
+	r.afterSwap(NewSwapConfig(opts...))
+}
+
+// callRefreshFunc invokes the refresher's current RefreshFunc. It is what
+// middleware wrap, such that swapping the RefreshFunc preserves their state.
+func (r *refresher[T]) callRefreshFunc(ctx context.Context, previous *Refreshable[T]) (*Refreshable[T], error) {
+	return (*r.swappableRefreshFunc.Load())(ctx, previous)
+}
+
+// getRefreshStrategy returns the refresher's current RefreshStrategy.
+func (r *refresher[T]) getRefreshStrategy() RefreshStrategy[T] {
+	return *r.swappableStrategy.Load()
+}
+
+// afterSwap triggers a refresh in the background if the given SwapConfig requires one.
+// A refresh already in progress (started before the swap) is waited for rather than
+// joined, such that the triggered refresh is guaranteed to use the new RefreshFunc.
+func (r *refresher[T]) afterSwap(c SwapConfig) {
+	if !c.RefreshImmediately {
+		return
+	}
+	r.ensureStarted()
+
+	r.flightMu.Lock()
+	inFlight := r.flight
+	r.flightMu.Unlock()
+
+	r.routinesMu.RLock()
+	defer r.routinesMu.RUnlock()
+
+	if r.refreshCtx.Err() != nil {
+		return
+	}
+	r.spawn(func() {
+		if inFlight != nil {
+			if _, err := inFlight.wait(r.refreshCtx); err != nil && r.refreshCtx.Err() != nil {
+				return
+			}
+		}
+		_, _ = r.refreshShared().wait(r.refreshCtx)
+	})
+}
@@ -0,0 +1,73 @@
+package refresh
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSetRefreshFuncReplacesValue(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	refreshFunc, issued := counter(time.Hour)
+	r := NewRefresher(func(ctx context.Context) (*Refreshable[int], error) {
+		if issued.Load() > 0 { // refreshes after the initial one block until released
+			entered <- struct{}{}
+			<-release
+		}
+		return refreshFunc(ctx)
+	})
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	values := make(chan int, 8)
+	unsubscribe := r.Subscribe(func(refreshable *Refreshable[int]) { values <- refreshable.Value })
+	defer unsubscribe()
+
+	// a refresh with the old RefreshFunc is in progress while it is replaced
+	go func() { _, _ = r.ForceRefresh(context.Background()) }()
+	<-entered
+	r.SetRefreshFunc(func(context.Context) (*Refreshable[int], error) {
+		now := time.Now()
+		return &Refreshable[int]{Value: -1, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+	}, WithImmediateRefresh())
+	close(release)
+
+	for _, expected := range []int{2, -1} {
+		select {
+		case value := <-values:
+			if value != expected {
+				t.Errorf("subscriber notified of %d, expected %d", value, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("subscriber not notified of %d", expected)
+		}
+	}
+	if current := r.GetCurrent(); current.Value != -1 {
+		t.Errorf("current value is %d, expected the new RefreshFunc's", current.Value)
+	}
+}
+
+func TestSetRefreshStrategyReschedules(t *testing.T) {
+	refreshFunc, _ := counter(time.Hour)
+	r := NewRefresher(refreshFunc)
+	defer r.Stop()
+	if err := r.WaitForInitialValue(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	values := make(chan *Refreshable[int], 1)
+	unsubscribe := r.Subscribe(func(refreshable *Refreshable[int]) { values <- refreshable })
+	defer unsubscribe()
+
+	r.SetRefreshStrategy(RefreshStrategyFromFunction(func(refreshable *Refreshable[int]) time.Time {
+		return refreshable.ExpiresAt.Add(-time.Minute)
+	}), WithImmediateRefresh())
+	select {
+	case refreshable := <-values:
+		if next, expected := r.GetNextRefreshTime(), refreshable.ExpiresAt.Add(-time.Minute); !next.Equal(expected) {
+			t.Errorf("next refresh at %s, expected %s as per the new strategy", next, expected)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscriber not notified after the swap")
+	}
+}
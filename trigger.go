@@ -0,0 +1,109 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrTriggerCycle is returned by TriggerOn when the requested trigger would result in
+// a cycle of refreshers triggering each other (and thus refreshing forever).
+var ErrTriggerCycle = errors.New("refresh trigger would result in a cycle")
+
+// triggers is the graph of refreshers triggering each other, keyed by refresher identity.
+var triggers = struct {
+	sync.Mutex
+	edges map[any]map[any]int // source -> dependent -> number of triggers
+}{edges: make(map[any]map[any]int)}
+
+// TriggerOn makes the dependent Refresher refresh whenever the source Refresher has a new value,
+// e.g. to mint a new service token whenever the signing key it is signed with is rotated. It
+// returns a function to undo the trigger, which is also undone once either Refresher stops.
+// The source does not wait for the dependent's refreshes, and triggers received while one is
+// pending are coalesced into it.
+//
+// If the dependent Refresher (directly or indirectly) already triggers refreshes of the source
+// Refresher, or they are the same Refresher, ErrTriggerCycle is returned instead. Refreshers are
+// identified by their (interface) value, so they must be comparable, as all pointers are.
+func TriggerOn[T, U any](dependent Refresher[T], source Refresher[U]) (cancel func(), err error) {
+	if err := addTrigger(source, dependent); err != nil {
+		return nil, err
+	}
+
+	// the dependent's refresh is not awaited, as that would stall the source's event handlers
+	refreshCtx, cancelRefreshes := context.WithCancel(context.Background())
+	refresh := func() {
+		go func() { _, _ = dependent.ForceRefresh(refreshCtx) }()
+	}
+	if r, ok := dependent.(*refresher[T]); ok {
+		refresh = r.trigger
+	}
+	unsubscribe := source.Subscribe(func(*Refreshable[U]) { refresh() })
+
+	var once sync.Once
+	cancelled := make(chan struct{})
+	cancel = func() {
+		once.Do(func() {
+			unsubscribe()
+			cancelRefreshes()
+			removeTrigger(source, dependent)
+			close(cancelled)
+		})
+	}
+	go func() {
+		select {
+		case <-source.Done():
+		case <-dependent.Done():
+		case <-cancelled:
+		}
+		cancel()
+	}()
+	return cancel, nil
+}
+
+// addTrigger adds an edge from source to dependent to the trigger graph,
+// unless it results in a cycle.
+func addTrigger(source, dependent any) error {
+	triggers.Lock()
+	defer triggers.Unlock()
+
+	if source == dependent || reaches(dependent, source, map[any]bool{}) {
+		return ErrTriggerCycle
+	}
+	if triggers.edges[source] == nil {
+		triggers.edges[source] = make(map[any]int)
+	}
+	triggers.edges[source][dependent]++
+	return nil
+}
+
+// removeTrigger removes an edge from source to dependent from the trigger graph.
+func removeTrigger(source, dependent any) {
+	triggers.Lock()
+	defer triggers.Unlock()
+
+	if triggers.edges[source][dependent]--; triggers.edges[source][dependent] <= 0 {
+		delete(triggers.edges[source], dependent)
+	}
+	if len(triggers.edges[source]) == 0 {
+		delete(triggers.edges, source)
+	}
+}
+
+// reaches returns true if there is a path from one refresher to another in the
+// trigger graph. triggers must be locked.
+func reaches(from, to any, visited map[any]bool) bool {
+	if from == to {
+		return true
+	}
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+	for next := range triggers.edges[from] {
+		if reaches(next, to, visited) {
+			return true
+		}
+	}
+	return false
+}
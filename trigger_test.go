@@ -0,0 +1,128 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTriggerOnRefreshesDependent(t *testing.T) {
+	sourceFunc, _ := counter(time.Hour)
+	source := NewRefresher(sourceFunc)
+	defer source.Stop()
+	dependentFunc, calls := counter(time.Hour)
+	dependent := NewRefresher(dependentFunc)
+	defer dependent.Stop()
+	for _, r := range []Refresher[int]{source, dependent} {
+		if err := r.WaitForInitialValue(time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancel, err := TriggerOn(dependent, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	before := calls.Load()
+	if _, err := source.ForceRefresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() == before {
+		if time.Now().After(deadline) {
+			t.Fatal("dependent was not refreshed after the source was")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTriggerOnDoesNotStallSource(t *testing.T) {
+	sourceFunc, _ := counter(time.Hour)
+	source := NewRefresher(sourceFunc, WithSynchronousCallbacks[int]())
+	defer source.Stop()
+
+	release := make(chan struct{})
+	defer close(release)
+	initialized := false
+	dependent := NewRefresher(func(ctx context.Context) (*Refreshable[int], error) {
+		if initialized {
+			<-release // triggered refreshes block until released
+		}
+		initialized = true
+		now := time.Now()
+		return &Refreshable[int]{Value: 1, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}, nil
+	})
+	defer dependent.Stop()
+	for _, r := range []Refresher[int]{source, dependent} {
+		if err := r.WaitForInitialValue(time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cancel, err := TriggerOn(dependent, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		for i := 0; i < 3; i++ {
+			_, _ = source.ForceRefresh(context.Background())
+		}
+	}()
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("source refresh waited for the dependent refresh")
+	}
+}
+
+func TestTriggerOnCycles(t *testing.T) {
+	newRefresher := func() Refresher[int] {
+		refreshFunc, _ := counter(time.Hour)
+		r := NewRefresher(refreshFunc, WithLazyStart[int]())
+		t.Cleanup(r.Stop)
+		return r
+	}
+	a, b, c := newRefresher(), newRefresher(), newRefresher()
+
+	if _, err := TriggerOn(a, a); !errors.Is(err, ErrTriggerCycle) {
+		t.Errorf("self trigger: got %v, expected ErrTriggerCycle", err)
+	}
+
+	cancelAB, err := TriggerOn(b, a) // a triggers b
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TriggerOn(a, b); !errors.Is(err, ErrTriggerCycle) {
+		t.Errorf("direct cycle: got %v, expected ErrTriggerCycle", err)
+	}
+
+	cancelBC, err := TriggerOn(c, b) // b triggers c
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancelBC()
+	if _, err := TriggerOn(a, c); !errors.Is(err, ErrTriggerCycle) {
+		t.Errorf("indirect cycle: got %v, expected ErrTriggerCycle", err)
+	}
+
+	// undoing a trigger removes it from the graph, such that it can be added again
+	cancelAB()
+	cancelAB() // idempotent
+	cancelCA, err := TriggerOn(a, c)
+	if err != nil {
+		t.Fatalf("trigger after cancelling the cycle's edge: %v", err)
+	}
+	cancelCA()
+	cancelAB, err = TriggerOn(b, a)
+	if err != nil {
+		t.Fatalf("re-adding a cancelled trigger: %v", err)
+	}
+	cancelAB()
+}
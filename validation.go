@@ -0,0 +1,38 @@
+package refresh
+
+import "fmt"
+
+// ValidateFunc returns a non-nil error if a Refreshable must not be used.
+type ValidateFunc[T any] func(*Refreshable[T]) error
+
+// WithValidator is the refresher Option to add a ValidateFunc which every new value must pass
+// before it becomes current, e.g. to check that a token has the required scopes, or that a
+// certificate chain verifies. A refreshed value failing validation is treated as a failed refresh
+// (and retried), and a value loaded from Storage failing validation as a failed storage read.
+// A renewed value failing validation is re-issued instead, as for a failed renewal.
+//
+// Validators are run in the order in which they were added.
+func WithValidator[T any](validate ValidateFunc[T]) Option[T] {
+	return func(r *refresher[T]) { r.validators = append(r.validators, validate) }
+}
+
+// ValidateTimestamps is a ValidateFunc which rejects values expiring before (or when) they were issued.
+func ValidateTimestamps[T any](refreshable *Refreshable[T]) error {
+	if !refreshable.ExpiresAt.After(refreshable.IssuedAt) {
+		return fmt.Errorf("value expires (%s) before it is issued (%s)", refreshable.ExpiresAt, refreshable.IssuedAt)
+	}
+	return nil
+}
+
+// validate runs all of the refresher's validators against a Refreshable.
+func (r *refresher[T]) validate(refreshable *Refreshable[T]) error {
+	if refreshable == nil {
+		return nil
+	}
+	for _, validate := range r.validators {
+		if err := validate(refreshable); err != nil {
+			return fmt.Errorf("invalid value: %v", err)
+		}
+	}
+	return nil
+}
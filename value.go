@@ -0,0 +1,37 @@
+package refresh
+
+import "fmt"
+
+// GetValue returns the current value, enforcing its expiry.
+func (r *refresher[T]) GetValue() (T, error) {
+	current, err := r.GetCurrentFresh()
+	if current == nil && err == nil {
+		err = r.wrapErr(ErrStale, nil) // withheld by StalePolicyReturnNil
+	}
+	return valueOf(current, err)
+}
+
+// MustValue returns the current value as per the refresher's StalePolicy, or panics.
+func (r *refresher[T]) MustValue() T {
+	return mustValueOf(r.GetCurrent())
+}
+
+// valueOf returns the value of a Refreshable returned alongside an error by GetCurrentFresh.
+func valueOf[T any](current *Refreshable[T], err error) (T, error) {
+	var zero T
+	if current == nil {
+		if err == nil {
+			err = ErrStale // withheld by StalePolicyReturnNil
+		}
+		return zero, err
+	}
+	return current.Value, err
+}
+
+// mustValueOf returns the value of a Refreshable returned by GetCurrent, or panics if it is nil.
+func mustValueOf[T any](current *Refreshable[T]) T {
+	if current == nil {
+		panic(fmt.Sprintf("refresh: MustValue called on a refresher without a usable value: %v", ErrNoValue))
+	}
+	return current.Value
+}
@@ -0,0 +1,81 @@
+package refresh
+
+import "time"
+
+// TimeWindow represents a (possibly recurring) period of time.
+type TimeWindow interface {
+	// Next returns the start and end of the occurrence of the window which contains
+	// the given time or, if none does, of the next occurrence after it. If there is
+	// no such occurrence, ok is false. Windows include their start but not their end.
+	Next(t time.Time) (start, end time.Time, ok bool)
+}
+
+// windowContains returns the end of the occurrence of the window
+// which contains the given time, if any.
+func windowContains(window TimeWindow, t time.Time) (time.Time, bool) {
+	start, end, ok := window.Next(t)
+	if !ok || t.Before(start) || !t.Before(end) {
+		return time.Time{}, false
+	}
+	return end, true
+}
+
+// absoluteTimeWindow is a TimeWindow which occurs only once.
+type absoluteTimeWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+// NewAbsoluteTimeWindow returns a TimeWindow which occurs once, between the given times
+// (e.g. a change-freeze period).
+func NewAbsoluteTimeWindow(start, end time.Time) TimeWindow {
+	return &absoluteTimeWindow{start: start, end: end}
+}
+
+// Next returns the window's only occurrence, if it has not ended by the given time.
+func (w *absoluteTimeWindow) Next(t time.Time) (time.Time, time.Time, bool) {
+	if !t.Before(w.end) {
+		return time.Time{}, time.Time{}, false
+	}
+	return w.start, w.end, true
+}
+
+// dailyTimeWindow is a TimeWindow which occurs every day.
+type dailyTimeWindow struct {
+	from     time.Duration
+	to       time.Duration
+	location *time.Location
+}
+
+// NewDailyTimeWindow returns a TimeWindow which occurs every day between the given offsets
+// from midnight in the given location, e.g. (2*time.Hour, 5*time.Hour, time.Local) for
+// 02:00-05:00 local time. If to is before from, the window spans midnight. A nil location
+// is interpreted as UTC.
+func NewDailyTimeWindow(from, to time.Duration, location *time.Location) TimeWindow {
+	if location == nil {
+		location = time.UTC
+	}
+	if to < from {
+		to += 24 * time.Hour
+	}
+	return &dailyTimeWindow{from: from, to: to, location: location}
+}
+
+// Next returns the occurrence of the window containing (or next after) the given time.
+func (w *dailyTimeWindow) Next(t time.Time) (time.Time, time.Time, bool) {
+	if w.from == w.to {
+		return time.Time{}, time.Time{}, false
+	}
+	local := t.In(w.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.location)
+
+	// an occurrence which started the day before may still be ongoing
+	for _, day := range []int{-1, 0, 1} {
+		dayStart := midnight.AddDate(0, 0, day)
+		start, end := dayStart.Add(w.from), dayStart.Add(w.to)
+		if t.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false // unreachable
+}
@@ -0,0 +1,62 @@
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONCodecID is the codec identifier of the ValueCodec returned by NewJSONValueCodec.
+const JSONCodecID = "json"
+
+// ValueCodec represents a mechanism for serializing values to bytes and back.
+type ValueCodec[T any] interface {
+	// ID returns the identifier of the codec, which is carried in envelopes such that
+	// consumers pick the right codec to decode values with.
+	ID() string
+
+	// Encode serializes a value.
+	Encode(T) ([]byte, error)
+
+	// Decode deserializes a value.
+	Decode([]byte) (T, error)
+}
+
+// ValueCodecFromFunctions builds a ValueCodec with the given identifier from functions.
+func ValueCodecFromFunctions[T any](
+	id string,
+	encode func(T) ([]byte, error),
+	decode func([]byte) (T, error),
+) ValueCodec[T] {
+	return &valueCodec[T]{id: id, encode: encode, decode: decode}
+}
+
+// valueCodec is a ValueCodec implemented by functions.
+type valueCodec[T any] struct {
+	id     string
+	encode func(T) ([]byte, error)
+	decode func([]byte) (T, error)
+}
+
+// ID returns the identifier of the codec.
+func (c *valueCodec[T]) ID() string { return c.id }
+
+// Encode serializes a value.
+func (c *valueCodec[T]) Encode(value T) ([]byte, error) { return c.encode(value) }
+
+// Decode deserializes a value.
+func (c *valueCodec[T]) Decode(data []byte) (T, error) { return c.decode(data) }
+
+// NewJSONValueCodec returns a ValueCodec which serializes values with encoding/json.
+func NewJSONValueCodec[T any]() ValueCodec[T] {
+	return ValueCodecFromFunctions(
+		JSONCodecID,
+		func(value T) ([]byte, error) { return json.Marshal(value) },
+		func(data []byte) (T, error) {
+			var value T
+			if err := json.Unmarshal(data, &value); err != nil {
+				return value, fmt.Errorf("failed to unmarshal json: %v", err)
+			}
+			return value, nil
+		},
+	)
+}
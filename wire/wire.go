@@ -0,0 +1,237 @@
+// Package wire defines the versioned envelope in which Refreshables are exchanged between
+// remote producers and consumers (e.g. a process serving its current value over HTTP or gRPC
+// to a fleet of consumers), along with the negotiation of the version to use between peers.
+//
+// Every envelope carries its schema version, such that consumers can decode envelopes of any
+// version they support, and producers can encode envelopes in the highest version supported
+// by both ends. This allows fleets with mixed versions of producers and consumers to keep
+// interoperating during rolling upgrades.
+package wire
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+// Version represents a version of the wire envelope schema.
+type Version int
+
+const (
+	// Version1 envelopes carry a JSON-encoded value and its timestamps.
+	Version1 Version = 1
+
+	// Version2 envelopes additionally carry the identifier of the codec with
+	// which the value was encoded, and the generation of the value.
+	Version2 Version = 2
+
+	// MinVersion is the oldest envelope version supported by this package.
+	MinVersion = Version1
+
+	// MaxVersion is the newest envelope version supported by this package.
+	MaxVersion = Version2
+)
+
+// VersionsHeader is the (HTTP or gRPC metadata) header with which a peer
+// advertises the envelope versions it supports, as formatted by FormatVersions.
+const VersionsHeader = "Refresh-Wire-Versions"
+
+// ErrNoCommonVersion is returned when two peers support no envelope version in common.
+var ErrNoCommonVersion = errors.New("no common wire version")
+
+// SupportedVersions returns all envelope versions supported by this package, newest first.
+func SupportedVersions() []Version {
+	versions := []Version{}
+	for v := MaxVersion; v >= MinVersion; v-- {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// Negotiate returns the newest envelope version in both the local and the remote sets
+// of supported versions. A nil (or empty) remote set is taken to be a peer which predates
+// negotiation, which is assumed to only support Version1.
+func Negotiate(local, remote []Version) (Version, error) {
+	if len(remote) == 0 {
+		remote = []Version{Version1}
+	}
+	supported := make(map[Version]bool, len(remote))
+	for _, v := range remote {
+		supported[v] = true
+	}
+	best := Version(0)
+	for _, v := range local {
+		if supported[v] && v > best {
+			best = v
+		}
+	}
+	if best == 0 {
+		return 0, fmt.Errorf("%v: local %v, remote %v", ErrNoCommonVersion, local, remote)
+	}
+	return best, nil
+}
+
+// FormatVersions formats a set of versions for the VersionsHeader e.g. "2,1".
+func FormatVersions(versions []Version) string {
+	strs := make([]string, 0, len(versions))
+	for _, v := range versions {
+		strs = append(strs, strconv.Itoa(int(v)))
+	}
+	return strings.Join(strs, ",")
+}
+
+// ParseVersions parses a set of versions from the VersionsHeader. Malformed entries are
+// skipped rather than rejected, such that newer peers may extend the header's syntax.
+func ParseVersions(header string) []Version {
+	versions := []Version{}
+	for _, str := range strings.Split(header, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(str))
+		if err != nil || n <= 0 {
+			continue
+		}
+		versions = append(versions, Version(n))
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	return versions
+}
+
+// Envelope is the wire representation of a Refreshable, with its value already encoded.
+type Envelope struct {
+	// Version is the schema version of the envelope.
+	Version Version
+
+	// Codec is the identifier of the ValueCodec with which Payload was encoded.
+	// Envelopes older than Version2 (and those with no codec) carry JSON-encoded values.
+	Codec string
+
+	// Generation is a counter which producers increment on every new value, such that
+	// consumers can discard values older than the one they hold. Envelopes older than
+	// Version2 carry no generation, which is decoded as zero.
+	Generation uint64
+
+	// IssuedAt, ExpiresAt, and RefreshAt are the timestamps of the Refreshable.
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	RefreshAt time.Time
+
+	// Payload is the encoded value.
+	Payload []byte
+}
+
+// envelope is the JSON representation of an Envelope. Fields are only ever added
+// (with omitempty) in newer versions, such that older consumers ignore them.
+type envelope struct {
+	Version    Version         `json:"v"`
+	Codec      string          `json:"codec,omitempty"`
+	Generation uint64          `json:"generation,omitempty"`
+	IssuedAt   time.Time       `json:"issued_at"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+	RefreshAt  *time.Time      `json:"refresh_at,omitempty"`
+	Value      json.RawMessage `json:"value,omitempty"`
+	Payload    []byte          `json:"payload,omitempty"`
+}
+
+// Marshal serializes an Envelope in the given version. Fields which the version
+// cannot represent are dropped, and values not encoded with the JSON ValueCodec
+// cannot be represented in envelopes older than Version2.
+func Marshal(env *Envelope, version Version) ([]byte, error) {
+	if version < MinVersion || version > MaxVersion {
+		return nil, fmt.Errorf("unsupported wire version %d", version)
+	}
+	out := &envelope{
+		Version:   version,
+		IssuedAt:  env.IssuedAt,
+		ExpiresAt: env.ExpiresAt,
+	}
+	if !env.RefreshAt.IsZero() {
+		refreshAt := env.RefreshAt
+		out.RefreshAt = &refreshAt
+	}
+	switch {
+	case version >= Version2:
+		out.Codec = env.Codec
+		out.Generation = env.Generation
+		out.Payload = env.Payload
+	case env.Codec == "" || env.Codec == JSONCodecID:
+		out.Value = env.Payload
+	default:
+		return nil, fmt.Errorf("values encoded with codec %q require wire version %d or newer", env.Codec, Version2)
+	}
+	return json.Marshal(out)
+}
+
+// Unmarshal deserializes an Envelope of any supported version.
+func Unmarshal(data []byte) (*Envelope, error) {
+	var in envelope
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("failed to decode envelope: %v", err)
+	}
+	if in.Version == 0 {
+		in.Version = Version1 // envelopes predating versioning
+	}
+	if in.Version < MinVersion || in.Version > MaxVersion {
+		return nil, fmt.Errorf("unsupported wire version %d (supported are %d to %d)", in.Version, MinVersion, MaxVersion)
+	}
+	env := &Envelope{
+		Version:    in.Version,
+		Codec:      JSONCodecID,
+		Generation: in.Generation,
+		IssuedAt:   in.IssuedAt,
+		ExpiresAt:  in.ExpiresAt,
+		Payload:    []byte(in.Value),
+	}
+	if in.RefreshAt != nil {
+		env.RefreshAt = *in.RefreshAt
+	}
+	if in.Version >= Version2 {
+		env.Payload = in.Payload
+		if in.Codec != "" {
+			env.Codec = in.Codec // otherwise JSON, as for envelopes older than Version2
+		}
+	}
+	return env, nil
+}
+
+// Wrap encodes a Refreshable's value with the given ValueCodec and returns its Envelope.
+// The envelope's version is left for Marshal to set.
+func Wrap[T any](refreshable *refresh.Refreshable[T], generation uint64, codec ValueCodec[T]) (*Envelope, error) {
+	payload, err := codec.Encode(refreshable.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %v", err)
+	}
+	return &Envelope{
+		Codec:      codec.ID(),
+		Generation: generation,
+		IssuedAt:   refreshable.IssuedAt,
+		ExpiresAt:  refreshable.ExpiresAt,
+		RefreshAt:  refreshable.RefreshAt,
+		Payload:    payload,
+	}, nil
+}
+
+// Unwrap decodes an Envelope's value with whichever of the given ValueCodecs matches the
+// codec identifier in the envelope, and returns the Refreshable alongside its generation.
+func Unwrap[T any](env *Envelope, codecs ...ValueCodec[T]) (*refresh.Refreshable[T], uint64, error) {
+	for _, codec := range codecs {
+		if codec.ID() != env.Codec {
+			continue
+		}
+		value, err := codec.Decode(env.Payload)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to decode value: %v", err)
+		}
+		return &refresh.Refreshable[T]{
+			Value:     value,
+			IssuedAt:  env.IssuedAt,
+			ExpiresAt: env.ExpiresAt,
+			RefreshAt: env.RefreshAt,
+		}, env.Generation, nil
+	}
+	return nil, 0, fmt.Errorf("no value codec for codec identifier %q", env.Codec)
+}
@@ -0,0 +1,166 @@
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianosela/refresh"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		local  []Version
+		remote []Version
+		want   Version
+	}{
+		{name: "both newest", local: SupportedVersions(), remote: []Version{Version2, Version1}, want: Version2},
+		{name: "older remote", local: SupportedVersions(), remote: []Version{Version1}, want: Version1},
+		{name: "older local", local: []Version{Version1}, remote: SupportedVersions(), want: Version1},
+		{name: "remote predates negotiation", local: SupportedVersions(), remote: nil, want: Version1},
+		{name: "newer remote", local: SupportedVersions(), remote: []Version{Version(3), Version2}, want: Version2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Negotiate(test.local, test.remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("negotiated version %d, expected %d", got, test.want)
+			}
+		})
+	}
+
+	if _, err := Negotiate([]Version{Version2}, []Version{Version1}); err == nil || !strings.Contains(err.Error(), ErrNoCommonVersion.Error()) {
+		t.Errorf("got %v for disjoint versions, expected %v", err, ErrNoCommonVersion)
+	}
+}
+
+func TestVersionsHeader(t *testing.T) {
+	header := FormatVersions(SupportedVersions())
+	if header != "2,1" {
+		t.Errorf("formatted versions %q, expected %q", header, "2,1")
+	}
+	if got := ParseVersions(header); !reflect.DeepEqual(got, SupportedVersions()) {
+		t.Errorf("parsed versions %v, expected %v", got, SupportedVersions())
+	}
+	if got, want := ParseVersions(" 1, x, 3;beta, -2, 0 ,2"), []Version{2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsed versions %v, expected malformed entries to be skipped: %v", got, want)
+	}
+}
+
+// upperCodec is a non-JSON ValueCodec for strings.
+var upperCodec = ValueCodecFromFunctions("upper",
+	func(value string) ([]byte, error) { return []byte(strings.ToUpper(value)), nil },
+	func(data []byte) (string, error) { return strings.ToLower(string(data)), nil },
+)
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	refreshable := &refresh.Refreshable[string]{
+		Value:     "token",
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Hour),
+		RefreshAt: now.Add(30 * time.Minute),
+	}
+	tests := []struct {
+		name           string
+		codec          ValueCodec[string]
+		version        Version
+		wantGeneration uint64
+	}{
+		{name: "json v1", codec: NewJSONValueCodec[string](), version: Version1, wantGeneration: 0},
+		{name: "json v2", codec: NewJSONValueCodec[string](), version: Version2, wantGeneration: 7},
+		{name: "custom codec v2", codec: upperCodec, version: Version2, wantGeneration: 7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env, err := Wrap(refreshable, 7, test.codec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := Marshal(env, test.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := Unmarshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Version != test.version {
+				t.Errorf("decoded version %d, expected %d", decoded.Version, test.version)
+			}
+			got, generation, err := Unwrap(decoded, NewJSONValueCodec[string](), upperCodec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != refreshable.Value || !got.IssuedAt.Equal(refreshable.IssuedAt) ||
+				!got.ExpiresAt.Equal(refreshable.ExpiresAt) || !got.RefreshAt.Equal(refreshable.RefreshAt) {
+				t.Errorf("decoded %+v, expected %+v", got, refreshable)
+			}
+			if generation != test.wantGeneration {
+				t.Errorf("decoded generation %d, expected %d", generation, test.wantGeneration)
+			}
+		})
+	}
+}
+
+func TestMarshalCustomCodecRequiresVersion2(t *testing.T) {
+	env, err := Wrap(&refresh.Refreshable[string]{Value: "token"}, 1, upperCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Marshal(env, Version1); err == nil {
+		t.Error("value encoded with a custom codec was marshalled in version 1")
+	}
+	for _, version := range []Version{0, -1, 3} {
+		if _, err := Marshal(env, version); err == nil {
+			t.Errorf("marshalled in unsupported version %d", version)
+		}
+	}
+}
+
+func TestUnmarshalCompatibility(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantVersion Version
+		wantPayload string
+	}{
+		{name: "unversioned", data: `{"issued_at":"2024-01-01T00:00:00Z","expires_at":"2024-01-01T01:00:00Z","value":"token"}`, wantVersion: Version1, wantPayload: `"token"`},
+		{name: "v1", data: `{"v":1,"issued_at":"2024-01-01T00:00:00Z","expires_at":"2024-01-01T01:00:00Z","value":"token"}`, wantVersion: Version1, wantPayload: `"token"`},
+		{name: "v2 without codec", data: `{"v":2,"issued_at":"2024-01-01T00:00:00Z","expires_at":"2024-01-01T01:00:00Z","payload":"InRva2VuIg=="}`, wantVersion: Version2, wantPayload: `"token"`},
+		{name: "v1 with unknown fields", data: `{"v":1,"issued_at":"2024-01-01T00:00:00Z","expires_at":"2024-01-01T01:00:00Z","value":"token","future":true}`, wantVersion: Version1, wantPayload: `"token"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env, err := Unmarshal([]byte(test.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if env.Version != test.wantVersion || env.Codec != JSONCodecID || !bytes.Equal(env.Payload, []byte(test.wantPayload)) {
+				t.Errorf("decoded version %d, codec %q, payload %q", env.Version, env.Codec, env.Payload)
+			}
+			got, _, err := Unwrap(env, NewJSONValueCodec[string]())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != "token" {
+				t.Errorf("decoded value %q, expected %q", got.Value, "token")
+			}
+		})
+	}
+
+	for _, data := range []string{`{"v":-1}`, `{"v":3}`, `not json`} {
+		if _, err := Unmarshal([]byte(data)); err == nil {
+			t.Errorf("%s was accepted", data)
+		}
+	}
+	if _, _, err := Unwrap(&Envelope{Version: Version2, Codec: "upper"}, NewJSONValueCodec[string]()); err == nil {
+		t.Error("envelope with no matching codec was decoded")
+	}
+}